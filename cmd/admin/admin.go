@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"grpcourse/cmd/middleware"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Admin - operator endpoints for a running server. Over HTTP: pprof under
+// /debug/pprof/, the log level on /loglevel and the calls in progress on
+// /rpcs. gRPC requests on the same port reach the channelz service
+// (grpc.channelz.v1.Channelz). Every request needs an admin bearer token.
+type Admin struct {
+	auth     *middleware.Authenticator
+	inflight *middleware.Tracker
+	logger   *logrus.Logger
+	gs       *grpc.Server
+}
+
+// New - turns channelz on, so call it before creating the servers to inspect
+func New(auth *middleware.Authenticator, inflight *middleware.Tracker, logger *logrus.Logger) *Admin {
+
+	a := &Admin{auth: auth, inflight: inflight, logger: logger}
+
+	a.gs = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+			if err := a.authorize(tokenFromContext(ctx)); err != nil {
+				return nil, status.Errorf(codes.Unauthenticated, "%v", err)
+			}
+
+			return handler(ctx, req)
+		}),
+	)
+
+	channelz.RegisterChannelzServiceToServer(a.gs)
+
+	return a
+}
+
+// Handler - serve it with h2c or TLS so gRPC clients reach channelz
+func (a *Admin) Handler() http.Handler {
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	mux.HandleFunc("/loglevel", a.logLevel)
+	mux.HandleFunc("/rpcs", a.rpcs)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// the gRPC interceptor answers for channelz with a proper status
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			a.gs.ServeHTTP(w, r)
+			return
+		}
+
+		if err := a.authorize(r.Header.Get("Authorization")); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Stop - closes channelz streams still open
+func (a *Admin) Stop() {
+	a.gs.Stop()
+}
+
+// level - body of /loglevel
+type level struct {
+	Level string `json:"level"`
+}
+
+// logLevel - GET returns the current level, PUT {"level":"debug"} changes it
+func (a *Admin) logLevel(w http.ResponseWriter, r *http.Request) {
+
+	switch r.Method {
+	case http.MethodGet:
+
+	case http.MethodPut, http.MethodPost:
+
+		var req level
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("cannot decode body : %v", err), http.StatusBadRequest)
+			return
+		}
+
+		lvl, err := logrus.ParseLevel(req.Level)
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if lvl != a.logger.GetLevel() {
+			a.logger.Warnf("log level changed from %v to %v", a.logger.GetLevel(), lvl)
+			a.logger.SetLevel(lvl)
+		}
+
+	default:
+		http.Error(w, "use GET or PUT", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, level{Level: a.logger.GetLevel().String()})
+}
+
+// rpc - a call in progress as listed by /rpcs
+type rpc struct {
+	Method  string    `json:"method"`
+	Peer    string    `json:"peer"`
+	Started time.Time `json:"started"`
+	Age     string    `json:"age"`
+}
+
+// rpcs - the calls in progress, oldest first
+func (a *Admin) rpcs(w http.ResponseWriter, r *http.Request) {
+
+	calls := a.inflight.Active()
+
+	list := make([]rpc, 0, len(calls))
+
+	for _, c := range calls {
+		list = append(list, rpc{
+			Method:  c.Method,
+			Peer:    c.Peer,
+			Started: c.Started,
+			Age:     time.Since(c.Started).Round(time.Millisecond).String(),
+		})
+	}
+
+	writeJSON(w, list)
+}
+
+// authorize - header is `Bearer <token>`, the token must carry the admin claim
+func (a *Admin) authorize(header string) error {
+
+	parts := strings.SplitN(header, " ", 2)
+
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return fmt.Errorf("admin bearer token required")
+	}
+
+	claims, err := a.auth.Verify(strings.TrimSpace(parts[1]))
+
+	if err != nil {
+		return fmt.Errorf("invalid token : %v", err)
+	}
+
+	if !claims.Admin {
+		return fmt.Errorf("%v is not an admin", claims.Subject)
+	}
+
+	return nil
+}
+
+// tokenFromContext - the authorization metadata of a gRPC call
+func tokenFromContext(ctx context.Context) string {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get("authorization"); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"grpcourse/cmd/middleware"
+	"grpcourse/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAdminRequiresAdminToken(t *testing.T) {
+
+	auth := middleware.NewAuthenticator(config.Auth{Enabled: true, SigningKey: "test-key"})
+	logger := logrus.New()
+
+	h := New(auth, middleware.NewTracker(), logger).Handler()
+
+	user, _ := auth.Issue("1001", false, time.Minute)
+	admin, _ := auth.Issue("ops", true, time.Minute)
+
+	for _, tc := range []struct {
+		token string
+		code  int
+	}{
+		{"", http.StatusUnauthorized},
+		{"not-a-token", http.StatusUnauthorized},
+		{user, http.StatusUnauthorized},
+		{admin, http.StatusOK},
+	} {
+
+		req := httptest.NewRequest(http.MethodGet, "/rpcs", nil)
+
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tc.code {
+			t.Errorf("token %.10q : expected %v, got %v", tc.token, tc.code, rec.Code)
+		}
+	}
+
+	// the level changes at runtime
+	req := httptest.NewRequest(http.MethodPut, "/loglevel", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Authorization", "Bearer "+admin)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("level not changed : %v %v", rec.Code, rec.Body)
+	}
+}
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"grpcourse/cmd/client"
+	"grpcourse/cmd/listen"
+	"grpcourse/cmd/tracing"
 	blogpb "grpcourse/data/protos/blog"
 	"grpcourse/data/protos/greet"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -20,52 +26,107 @@ var grpcClient = &cobra.Command{
 	},
 }
 
+var (
+	addr       string
+	token      string
+	tenantID   string
+	caCert     string
+	clientCert string
+	clientKey  string
+	plaintext  bool
+)
+
 func init() {
+	grpcClient.PersistentFlags().StringVar(&addr, "addr", "localhost:50051", "server address, host:port or unix:///path/to.sock")
+	grpcClient.PersistentFlags().StringVar(&token, "token", os.Getenv("GRPCOURSE_TOKEN"), "bearer token sent with every call (see the token command)")
+	grpcClient.PersistentFlags().StringVar(&tenantID, "tenant", "", "tenant sent with every call, when the token is not bound to one")
+	grpcClient.PersistentFlags().StringVar(&caCert, "ca", "ssl/ca.crt", "CA certificate trusted to verify the server")
+	grpcClient.PersistentFlags().StringVar(&clientCert, "cert", "", "client certificate presented for mutual TLS")
+	grpcClient.PersistentFlags().StringVar(&clientKey, "key", "", "private key of the client certificate")
+	grpcClient.PersistentFlags().BoolVar(&plaintext, "plaintext", false, "connect without TLS (servers running with tls disabled)")
+
 	rootCmd.AddCommand(grpcClient)
 }
 
 func start() {
 
+	// every helper starts a root span, exported as configured in tracing
+	shutdown, err := tracing.Setup(cfg.Tracing, "grpcourse-client")
+
+	if err != nil {
+		log.Fatalf("cannot set up tracing : %v", err)
+	}
+
+	defer shutdown(context.Background())
+
+	conn := dial()
+
+	defer conn.Close()
+
+	// 1. Coursework - greet and calculator
+	// gclient := greet.NewGreetServiceClient(conn)
+	// exercise(gclient) // comment or uncomment
+
+	// 2. blog service
+	bclient := blogpb.NewBlogServiceClient(conn)
+	client.DoCreateBlog(bclient) // NB: use created id when reading/updating
+	// client.DoReadBlog(bclient)
+	// client.DoUpdateBlog(bclient)
+	// client.DoDeleteBlog(bclient)
+	client.DoFetchBlogs(bclient)
+}
+
+// dial - connects to the server using the client flags
+func dial() *grpc.ClientConn {
+
 	// by default
-	tls := true
+	tls := !plaintext
 
-	opts := grpc.WithInsecure()
+	opts := []grpc.DialOption{grpc.WithInsecure()}
 
 	if tls {
 
-		cert := "ssl/ca.crt" // Certificate Authority Trust
-
-		creds, err := credentials.NewClientTLSFromFile(cert, "")
+		// Certificate Authority Trust, plus our own certificate under mutual TLS
+		creds, err := clientCredentials(caCert, clientCert, clientKey)
 
 		if err != nil {
 			log.Fatalf("could not construct TLS credentials : %v", err)
-			return
 		}
 
-		opts = grpc.WithTransportCredentials(creds)
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+
+	// trace context travels in the request metadata
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+	)
+
+	if token != "" {
+
+		var creds credentials.PerRPCCredentials = client.BearerToken(token)
+
+		// the local sidecar case, plaintext is fine on a unix socket only
+		if plaintext && strings.HasPrefix(addr, listen.Unix+":") {
+			creds = client.LocalBearerToken(token)
+		}
+
+		opts = append(opts, grpc.WithPerRPCCredentials(creds))
+	}
+
+	if tenantID != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(client.Tenant{Header: cfg.Tenancy.Header, ID: tenantID}))
 	}
 
 	// grpc.Dial(target should be explicit rather than the port)
 	// eg. "localhost:PORT" rather than ":PORT"
-	conn, err := grpc.Dial("localhost:50051", opts)
+	conn, err := grpc.Dial(addr, opts...)
 
 	if err != nil {
 		panic("could not establish connection")
 	}
 
-	defer conn.Close()
-
-	// 1. Coursework - greet and calculator
-	// gclient := greet.NewGreetServiceClient(conn)
-	// exercise(gclient) // comment or uncomment
-
-	// 2. blog service
-	bclient := blogpb.NewBlogServiceClient(conn)
-	client.DoCreateBlog(bclient) // NB: use created id when reading/updating
-	// client.DoReadBlog(bclient)
-	// client.DoUpdateBlog(bclient)
-	// client.DoDeleteBlog(bclient)
-	client.DoFetchBlogs(bclient)
+	return conn
 }
 
 func exercise(c greet.GreetServiceClient) {
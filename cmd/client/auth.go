@@ -0,0 +1,48 @@
+package client
+
+import "context"
+
+// BearerToken - per RPC credentials sending a JWT in the `authorization` metadata
+type BearerToken string
+
+// GetRequestMetadata -
+func (t BearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity - tokens are never sent in plain text
+func (t BearerToken) RequireTransportSecurity() bool {
+	return true
+}
+
+// LocalBearerToken - BearerToken for a plaintext unix socket, which never
+// leaves the host so there is no transport security to require
+type LocalBearerToken string
+
+// GetRequestMetadata -
+func (t LocalBearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return BearerToken(t).GetRequestMetadata(ctx, uri...)
+}
+
+// RequireTransportSecurity -
+func (t LocalBearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// Tenant - per RPC credentials naming the tenant in the Header metadata
+type Tenant struct {
+	Header string
+	ID     string
+}
+
+// GetRequestMetadata -
+func (t Tenant) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+
+	return map[string]string{t.Header: t.ID}, nil
+}
+
+// RequireTransportSecurity - the tenant id is not a secret
+func (t Tenant) RequireTransportSecurity() bool {
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"grpcourse/cmd/tracing"
 	blogpb "grpcourse/data/protos/blog"
 	"io"
 	"log"
@@ -17,6 +18,10 @@ import (
 // DoCreateBlog -
 func DoCreateBlog(client blogpb.BlogServiceClient) {
 
+	// root span - the upload RPC and the server side spans join this trace
+	ctx, span := tracing.Start(context.Background(), "DoCreateBlog")
+	defer span.End()
+
 	fmt.Println("Creating blog ....")
 
 	// 1. prepare blog
@@ -34,7 +39,7 @@ func DoCreateBlog(client blogpb.BlogServiceClient) {
 	}
 
 	// 2. instantiate createblog stream - time out after 5 sec without response
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
 	stream, err := client.CreateBlog(ctx)
@@ -109,13 +114,16 @@ func DoCreateBlog(client blogpb.BlogServiceClient) {
 // DoReadBlog -
 func DoReadBlog(client blogpb.BlogServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoReadBlog")
+	defer span.End()
+
 	fmt.Println("Fetching blog ....")
 
 	req := &blogpb.ReadBlogRequest{
 		Id: "5f2011c0f7bc9e1a387c2a1e",
 	}
 
-	res, err := client.ReadBlog(context.Background(), req)
+	res, err := client.ReadBlog(ctx, req)
 
 	if err != nil {
 
@@ -126,6 +134,7 @@ func DoReadBlog(client blogpb.BlogServiceClient) {
 		}
 
 		fmt.Printf("could not fetch blog : %v\n", err)
+		printStatus(err)
 
 		return
 	}
@@ -137,6 +146,9 @@ func DoReadBlog(client blogpb.BlogServiceClient) {
 // rather than streaming the image to server, we'll send raw bytes at a go
 func DoUpdateBlog(client blogpb.BlogServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoUpdateBlog")
+	defer span.End()
+
 	fmt.Println("Updating blog ....")
 
 	// 1. Prepare image into bytes slice
@@ -167,7 +179,7 @@ func DoUpdateBlog(client blogpb.BlogServiceClient) {
 		Image: buffer,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 
 	// 3. update blog
@@ -181,6 +193,7 @@ func DoUpdateBlog(client blogpb.BlogServiceClient) {
 		}
 
 		fmt.Printf("cannot update blog : %v\n", err)
+		printStatus(err)
 		return
 	}
 
@@ -191,11 +204,14 @@ func DoUpdateBlog(client blogpb.BlogServiceClient) {
 // DoDeleteBlog - deletes a blog by id
 func DoDeleteBlog(client blogpb.BlogServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoDeleteBlog")
+	defer span.End()
+
 	fmt.Println("Deleting blog ....")
 
 	req := &blogpb.DeleteBlogRequest{Id: "5f202d6a64dfb5ea04078b6b"}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	res, err := client.DeleteBlog(ctx, req)
@@ -213,6 +229,7 @@ func DoDeleteBlog(client blogpb.BlogServiceClient) {
 		}
 
 		fmt.Printf("could not delete blog : %v\n", err)
+		printStatus(err)
 
 		return
 	}
@@ -223,12 +240,15 @@ func DoDeleteBlog(client blogpb.BlogServiceClient) {
 // DoFetchBlogs - fetches lots of blogs
 func DoFetchBlogs(client blogpb.BlogServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoFetchBlogs")
+	defer span.End()
+
 	fmt.Println("Listing blogs ....")
 
 	req := &blogpb.ListBlogRequest{}
 
 	// timeout after 10seconds
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	res, err := client.ListBlog(ctx, req)
@@ -242,6 +262,7 @@ func DoFetchBlogs(client blogpb.BlogServiceClient) {
 		}
 
 		fmt.Printf("cannot fetch blogs : %v\n", err)
+		printStatus(err)
 		return
 	}
 
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// printStatus - prints the status code of a failed call along with
+// any field violations or resource info the server attached to it
+func printStatus(err error) {
+
+	st, ok := status.FromError(err)
+
+	if !ok {
+		return
+	}
+
+	fmt.Printf("status : %v\n", st.Code())
+
+	for _, d := range st.Details() {
+
+		switch info := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range info.GetFieldViolations() {
+				fmt.Printf("  invalid field %q : %s\n", v.GetField(), v.GetDescription())
+			}
+
+		case *errdetails.ResourceInfo:
+			fmt.Printf("  resource %s %q : %s\n", info.GetResourceType(), info.GetResourceName(), info.GetDescription())
+		}
+	}
+}
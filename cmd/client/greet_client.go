@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"grpcourse/cmd/tracing"
 	"grpcourse/data/protos/greet"
 	"io"
 	"log"
@@ -16,10 +17,13 @@ import (
 // DoUnary - Unary RPC implementation for Greet
 func DoUnary(c greet.GreetServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoUnary")
+	defer span.End()
+
 	req1 := &greet.GreetRequest{Greeting: &greet.Greeting{FirstName: "Jon", SecondName: "Snow"}}
 
 	// Deadlines with gRPC, create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 
 	defer cancel()
 
@@ -47,10 +51,13 @@ func DoUnary(c greet.GreetServiceClient) {
 // DoUnarySum -
 func DoUnarySum(c greet.GreetServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoUnarySum")
+	defer span.End()
+
 	req2 := &greet.SumRequest{A: 10, B: 10}
 
 	// Deadlines with gRPC, create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 
 	defer cancel()
 
@@ -78,11 +85,14 @@ func DoUnarySum(c greet.GreetServiceClient) {
 // DoGreetStream - stream data from server
 func DoGreetStream(c greet.GreetServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoGreetStream")
+	defer span.End()
+
 	req := &greet.GreetRequest{
 		Greeting: &greet.Greeting{FirstName: "Jane", SecondName: "Doe"},
 	}
 
-	stream, err := c.GreetAlot(context.Background(), req)
+	stream, err := c.GreetAlot(ctx, req)
 
 	if err != nil {
 		log.Fatalf("cannot stream greetings : %v", err)
@@ -111,9 +121,12 @@ func DoGreetStream(c greet.GreetServiceClient) {
 // DoPMStream - server streams prime number factors to the client
 func DoPMStream(c greet.GreetServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoPMStream")
+	defer span.End()
+
 	req := &greet.PMRequest{Number: 120}
 
-	stream, err := c.PrimeNumberDecomposition(context.Background(), req)
+	stream, err := c.PrimeNumberDecomposition(ctx, req)
 
 	if err != nil {
 		log.Fatalf("cannot stream prime numbers : %v", err)
@@ -140,7 +153,10 @@ func DoPMStream(c greet.GreetServiceClient) {
 // DoClientStreaming - stream data to server
 func DoClientStreaming(c greet.GreetServiceClient) {
 
-	lc, err := c.LongGreet(context.Background())
+	ctx, span := tracing.Start(context.Background(), "DoClientStreaming")
+	defer span.End()
+
+	lc, err := c.LongGreet(ctx)
 
 	if err != nil {
 		log.Fatalf("cannot create long greeting client : %v", err)
@@ -174,7 +190,10 @@ func DoClientStreaming(c greet.GreetServiceClient) {
 // server computes average and sends the response
 func DoComputeAverage(c greet.GreetServiceClient) {
 
-	ca, err := c.ComputeAverage(context.Background())
+	ctx, span := tracing.Start(context.Background(), "DoComputeAverage")
+	defer span.End()
+
+	ca, err := c.ComputeAverage(ctx)
 
 	var vals []int
 
@@ -206,7 +225,10 @@ func DoComputeAverage(c greet.GreetServiceClient) {
 // DoBiDiStreaming - it's a two way streaming feature
 func DoBiDiStreaming(c greet.GreetServiceClient) {
 
-	bic, err := c.GreetEveryone(context.Background())
+	ctx, span := tracing.Start(context.Background(), "DoBiDiStreaming")
+	defer span.End()
+
+	bic, err := c.GreetEveryone(ctx)
 
 	if err != nil {
 		log.Fatalf("cannot create bi-di client stream : %v", err)
@@ -273,9 +295,12 @@ func DoBiDiStreaming(c greet.GreetServiceClient) {
 // DoSquareRoot -
 func DoSquareRoot(c greet.GreetServiceClient) {
 
+	ctx, span := tracing.Start(context.Background(), "DoSquareRoot")
+	defer span.End()
+
 	req := &greet.SquareRootRequest{Number: 25}
 
-	sqc, err := c.SquareRoot(context.Background(), req)
+	sqc, err := c.SquareRoot(ctx, req)
 
 	if err != nil {
 
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"grpcourse/cmd/gateway"
+	"grpcourse/cmd/pki"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+var gatewayCmd = &cobra.Command{
+	Use:     "Gateway",
+	Aliases: []string{"gateway", "gw"},
+	Short:   "REST/JSON gateway in front of a running gRPC server",
+	Run: func(cmd *cobra.Command, args []string) {
+		serveGateway()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gatewayCmd)
+}
+
+// serveGateway - runs the gateway on its own until interrupted
+func serveGateway() {
+
+	logger := logrus.New()
+
+	// gateway.addr is usually empty as the server mounts the gateway on its own port
+	if cfg.Gateway.Addr == "" {
+		cfg.Gateway.Addr = ":8080"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var reloader *pki.Reloader
+
+	if cfg.Gateway.TLS {
+
+		var err error
+
+		if reloader, err = pki.NewReloader(cfg.TLS.Cert, cfg.TLS.Key, logger); err != nil {
+			logger.Fatalf("cannot load gateway certificate : %v", err)
+		}
+
+		if cfg.TLS.ReloadInterval > 0 {
+			go reloader.Watch(ctx, time.Duration(cfg.TLS.ReloadInterval))
+		}
+	}
+
+	srv, err := startGateway(ctx, reloader, logger)
+
+	if err != nil {
+		logger.Fatalf("cannot start gateway : %v", err)
+	}
+
+	fmt.Println("[ EXIT ] Press CTRL + C ...")
+
+	kill := make(chan os.Signal, 1)
+	signal.Notify(kill, os.Interrupt)
+
+	<-kill
+
+	srv.Close()
+}
+
+// gatewayHandler - REST handler proxying to cfg.Gateway.Upstream.
+// The upstream connection is closed when ctx is done.
+func gatewayHandler(ctx context.Context, logger *logrus.Logger) (http.Handler, error) {
+
+	c := cfg.Gateway
+
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+	}
+
+	// the upstream speaks TLS whenever the server does
+	if cfg.TLS.Enabled {
+
+		creds, err := clientCredentials(c.CA, c.Cert, c.Key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		opts[0] = grpc.WithTransportCredentials(creds)
+	}
+
+	conn, err := grpc.Dial(c.Upstream, opts...)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot dial %v : %v", c.Upstream, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	return gateway.New(ctx, conn, c.OpenAPIDir, cfg.Tenancy.Header, logger)
+}
+
+// startGateway - serves the REST gateway on its own port, cfg.Gateway.Addr.
+// With gateway TLS on, reloader provides the certificate.
+func startGateway(ctx context.Context, reloader *pki.Reloader, logger *logrus.Logger) (*http.Server, error) {
+
+	c := cfg.Gateway
+
+	handler, err := gatewayHandler(ctx, logger)
+
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{Addr: c.Addr, Handler: handler}
+
+	if c.TLS {
+
+		if reloader == nil {
+			return nil, fmt.Errorf("gateway tls needs the server certificate")
+		}
+
+		srv.TLSConfig = &tls.Config{GetCertificate: reloader.GetCertificate, MinVersion: tls.VersionTLS12}
+	}
+
+	go func() {
+
+		logger.Infof("REST gateway listening on %v", c.Addr)
+
+		var err error
+
+		if c.TLS {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorf("gateway stopped : %v", err)
+		}
+	}()
+
+	return srv, nil
+}
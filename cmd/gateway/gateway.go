@@ -0,0 +1,318 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	auditpb "grpcourse/data/protos/audit"
+	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/protos/greet"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// uploadChunk - image bytes per CreateBlog message, the gRPC client uses the same size
+const uploadChunk = 1024
+
+// maxField - size limit of a text field in a multipart upload
+const maxField = 64 << 10
+
+// Gateway - REST/JSON front end for GreetService, BlogService and AuditService. Annotated
+// RPCs are served by the generated handlers, image upload and download
+// by the multipart and chunked handlers below.
+type Gateway struct {
+	mux    *runtime.ServeMux
+	blogs  blogpb.BlogServiceClient
+	logger *logrus.Logger
+}
+
+// New - returns the gateway handler proxying to the gRPC server on conn.
+// Generated OpenAPI documents in openAPIDir are served under /openapi/.
+// tenantHeader (tenancy.header) is passed on to the server, empty for none.
+func New(ctx context.Context, conn *grpc.ClientConn, openAPIDir, tenantHeader string, logger *logrus.Logger) (http.Handler, error) {
+
+	// proto field names and zero values, same as the documents describe
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{OrigName: true, EmitDefaults: true}),
+		runtime.WithIncomingHeaderMatcher(incomingHeader(tenantHeader)),
+	)
+
+	if err := greet.RegisterGreetServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("cannot register greet handlers : %v", err)
+	}
+
+	if err := blogpb.RegisterBlogServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("cannot register blog handlers : %v", err)
+	}
+
+	if err := auditpb.RegisterAuditServiceHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("cannot register audit handlers : %v", err)
+	}
+
+	g := &Gateway{mux: mux, blogs: blogpb.NewBlogServiceClient(conn), logger: logger}
+
+	root := http.NewServeMux()
+	root.Handle("/openapi/", http.StripPrefix("/openapi/", http.FileServer(http.Dir(openAPIDir))))
+	root.Handle("/", g)
+
+	return root, nil
+}
+
+// incomingHeader - the default mapping plus X-Request-Id, so REST callers can
+// correlate their requests with the audit trail, and the tenant header
+func incomingHeader(tenantHeader string) runtime.HeaderMatcherFunc {
+
+	tenantHeader = strings.ToLower(tenantHeader)
+
+	return func(key string) (string, bool) {
+
+		switch k := strings.ToLower(key); {
+		case k == "x-request-id", tenantHeader != "" && k == tenantHeader:
+			return k, true
+		}
+
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// ServeHTTP - routes image transfers to the streaming handlers, everything else to the generated ones
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/blogs" {
+		g.upload(w, r)
+		return
+	}
+
+	if id, ok := imagePath(r.URL.Path); ok && r.Method == http.MethodGet {
+		g.download(w, r, id)
+		return
+	}
+
+	g.mux.ServeHTTP(w, r)
+}
+
+// upload - POST /v1/blogs as multipart/form-data with the title, body and
+// author_id fields followed by the image file, streamed to CreateBlog
+func (g *Gateway) upload(w http.ResponseWriter, r *http.Request) {
+
+	_, out := runtime.MarshalerForRequest(g.mux, r)
+
+	// forwards the authorization header, deadlines and tracing the same way as generated handlers
+	ctx, err := runtime.AnnotateContext(r.Context(), g.mux, r)
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	mr, err := r.MultipartReader()
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, status.Errorf(codes.InvalidArgument, "expected multipart/form-data : %v", err))
+		return
+	}
+
+	blog := &blogpb.Blog{}
+
+	var stream blogpb.BlogService_CreateBlogClient
+
+	for {
+
+		part, err := mr.NextPart()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			runtime.HTTPError(ctx, g.mux, out, w, r, status.Errorf(codes.InvalidArgument, "cannot read multipart body : %v", err))
+			return
+		}
+
+		switch part.FormName() {
+		case "title", "body", "author_id":
+
+			if stream != nil {
+				runtime.HTTPError(ctx, g.mux, out, w, r, status.Errorf(codes.InvalidArgument, "field %v must come before the image", part.FormName()))
+				return
+			}
+
+			value, err := ioutil.ReadAll(io.LimitReader(part, maxField))
+
+			if err != nil {
+				runtime.HTTPError(ctx, g.mux, out, w, r, status.Errorf(codes.InvalidArgument, "cannot read field %v : %v", part.FormName(), err))
+				return
+			}
+
+			setField(blog, part.FormName(), string(value))
+
+		case "image":
+
+			if stream != nil {
+				runtime.HTTPError(ctx, g.mux, out, w, r, status.Errorf(codes.InvalidArgument, "only one image may be uploaded"))
+				return
+			}
+
+			blog.ImagePath = part.FileName()
+
+			if stream, err = g.send(ctx, blog, part); err != nil {
+				runtime.HTTPError(ctx, g.mux, out, w, r, err)
+				return
+			}
+		}
+	}
+
+	// a blog without an image
+	if stream == nil {
+
+		if stream, err = g.send(ctx, blog, nil); err != nil {
+			runtime.HTTPError(ctx, g.mux, out, w, r, err)
+			return
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	body, err := out.Marshal(res)
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", out.ContentType())
+	w.Write(body)
+}
+
+// send - opens CreateBlog and streams the blog followed by the image read from image
+func (g *Gateway) send(ctx context.Context, blog *blogpb.Blog, image io.Reader) (blogpb.BlogService_CreateBlogClient, error) {
+
+	stream, err := g.blogs.CreateBlog(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Send(&blogpb.CreateBlogRequest{Data: &blogpb.CreateBlogRequest_Blog{Blog: blog}}); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if image == nil {
+		return stream, nil
+	}
+
+	buf := make([]byte, uploadChunk)
+
+	for {
+
+		n, err := image.Read(buf)
+
+		if n > 0 {
+
+			// io.EOF - the server gave up (eg. image too large), CloseAndRecv has the reason
+			if err := stream.Send(&blogpb.CreateBlogRequest{Data: &blogpb.CreateBlogRequest_Image{Image: buf[:n]}}); err == io.EOF {
+				return stream, nil
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		if err == io.EOF {
+			return stream, nil
+		}
+
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "cannot read image : %v", err)
+		}
+	}
+}
+
+// download - GET /v1/blogs/{id}/image?variant= streamed from DownloadImage as a chunked response
+func (g *Gateway) download(w http.ResponseWriter, r *http.Request, id string) {
+
+	_, out := runtime.MarshalerForRequest(g.mux, r)
+
+	ctx, err := runtime.AnnotateContext(r.Context(), g.mux, r)
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	stream, err := g.blogs.DownloadImage(ctx, &blogpb.DownloadImageRequest{Id: id, Variant: r.URL.Query().Get("variant")})
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	// errors before the first chunk still get a proper status code
+	res, err := stream.Recv()
+
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, out, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", res.GetContentType())
+
+	flusher, _ := w.(http.Flusher)
+
+	for {
+
+		if _, err := w.Write(res.GetChunk()); err != nil {
+			g.logger.Errorf("cannot write image chunk : %v", err)
+			return
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if res, err = stream.Recv(); err == io.EOF {
+			return
+		}
+
+		// headers are gone, all we can do is cut the response short
+		if err != nil {
+			g.logger.Errorf("image download of %v failed : %v", id, err)
+			return
+		}
+	}
+}
+
+// imagePath - blog id of a /v1/blogs/{id}/image path
+func imagePath(path string) (string, bool) {
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(parts) != 4 || parts[0] != "v1" || parts[1] != "blogs" || parts[3] != "image" || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[2], true
+}
+
+func setField(blog *blogpb.Blog, name, value string) {
+
+	switch name {
+	case "title":
+		blog.Title = value
+	case "body":
+		blog.Body = value
+	case "author_id":
+		blog.AuthorId = value
+	}
+}
@@ -0,0 +1,179 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	blogpb "grpcourse/data/protos/blog"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// blogs - fake BlogService keeping the last upload in memory
+type blogs struct {
+	blogpb.UnimplementedBlogServiceServer
+	image   []byte
+	variant string
+}
+
+func (b *blogs) CreateBlog(stream blogpb.BlogService_CreateBlogServer) error {
+
+	req, err := stream.Recv()
+
+	if err != nil {
+		return err
+	}
+
+	blog := req.GetBlog()
+
+	b.image = nil
+
+	for {
+
+		req, err := stream.Recv()
+
+		if err == io.EOF {
+			blog.Id = "5f1b2c3d4e5f6a7b8c9d0e1f"
+			return stream.SendAndClose(&blogpb.CreateBlogResponse{Blog: blog})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		b.image = append(b.image, req.GetImage()...)
+	}
+}
+
+func (b *blogs) DownloadImage(req *blogpb.DownloadImageRequest, stream blogpb.BlogService_DownloadImageServer) error {
+
+	if req.GetId() != "5f1b2c3d4e5f6a7b8c9d0e1f" {
+		return status.Errorf(codes.NotFound, "blog %v not found", req.GetId())
+	}
+
+	b.variant = req.GetVariant()
+
+	stream.Send(&blogpb.DownloadImageResponse{ContentType: "image/png", Chunk: b.image[:2]})
+
+	return stream.Send(&blogpb.DownloadImageResponse{Chunk: b.image[2:]})
+}
+
+func TestImageTransfer(t *testing.T) {
+
+	lis := bufconn.Listen(1 << 20)
+
+	gs := grpc.NewServer()
+	fake := &blogs{}
+	blogpb.RegisterBlogServiceServer(gs, fake)
+
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	handler, err := New(context.Background(), conn, ".", "x-tenant-id", logrus.New())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	// 1. multipart upload, the image is larger than one chunk
+	image := bytes.Repeat([]byte("png!"), 1000)
+
+	body := new(bytes.Buffer)
+	form := multipart.NewWriter(body)
+	form.WriteField("title", "hello")
+	form.WriteField("body", "world")
+	part, _ := form.CreateFormFile("image", "cover.png")
+	part.Write(image)
+	form.Close()
+
+	res, err := http.Post(srv.URL+"/v1/blogs", form.FormDataContentType(), body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK || !strings.Contains(string(created), `"image_path":"cover.png"`) {
+		t.Fatalf("unexpected upload response %v %s", res.StatusCode, created)
+	}
+
+	if !bytes.Equal(fake.image, image) {
+		t.Fatalf("server received %v bytes, want %v", len(fake.image), len(image))
+	}
+
+	// 2. chunked download
+	res, err = http.Get(srv.URL + "/v1/blogs/5f1b2c3d4e5f6a7b8c9d0e1f/image?variant=thumbnail")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	downloaded, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.Header.Get("Content-Type") != "image/png" || !bytes.Equal(downloaded, image) {
+		t.Errorf("unexpected download %v of %v bytes", res.Header.Get("Content-Type"), len(downloaded))
+	}
+
+	if fake.variant != "thumbnail" {
+		t.Errorf("expected the thumbnail variant, got %q", fake.variant)
+	}
+
+	// 3. gRPC errors keep their HTTP mapping
+	res, err = http.Get(srv.URL + "/v1/blogs/missing/image")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %v", res.StatusCode)
+	}
+}
+
+func TestIncomingHeader(t *testing.T) {
+
+	match := incomingHeader("X-Team")
+
+	for key, want := range map[string]string{"X-Team": "x-team", "X-Request-Id": "x-request-id", "X-Tenant-Id": ""} {
+
+		got, ok := match(key)
+
+		if (want != "") != ok || (ok && got != want) {
+			t.Errorf("%v : expected %q, got %q %v", key, want, got, ok)
+		}
+	}
+
+	// no tenancy, no tenant header
+	if _, ok := incomingHeader("")(""); ok {
+		t.Errorf("empty header matched")
+	}
+}
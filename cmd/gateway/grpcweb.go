@@ -0,0 +1,41 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/improbable-eng/grpc-web/go/grpcweb"
+	"google.golang.org/grpc"
+)
+
+// GRPCWeb - serves the services registered on gs to browsers speaking
+// gRPC-Web (unary and server streaming calls), other requests go to next.
+// Cross origin requests are accepted from origins only, "*" accepts any origin.
+func GRPCWeb(gs *grpc.Server, origins []string, next http.Handler) http.Handler {
+
+	wrapped := grpcweb.WrapServer(gs, grpcweb.WithOriginFunc(func(origin string) bool {
+		return originAllowed(origins, origin)
+	}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if wrapped.IsGrpcWebRequest(r) || wrapped.IsAcceptableGrpcCorsRequest(r) {
+			wrapped.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// originAllowed - reports whether origin is in the allowed list
+func originAllowed(allowed []string, origin string) bool {
+
+	for _, o := range allowed {
+
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+
+	return false
+}
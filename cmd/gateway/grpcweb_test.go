@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/binary"
+	"grpcourse/data/protos/greet"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// greeter - fake GreetService streaming one greeting per letter of the first name
+type greeter struct {
+	greet.UnimplementedGreetServiceServer
+}
+
+func (*greeter) GreetAlot(req *greet.GreetRequest, stream greet.GreetService_GreetAlotServer) error {
+
+	for _, c := range req.GetGreeting().GetFirstName() {
+
+		if err := stream.Send(&greet.GreetResponse{Response: string(c)}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestGRPCWebServerStream(t *testing.T) {
+
+	gs := grpc.NewServer()
+	greet.RegisterGreetServiceServer(gs, &greeter{})
+
+	srv := httptest.NewServer(GRPCWeb(gs, []string{"http://localhost:3000"}, http.NotFoundHandler()))
+	defer srv.Close()
+
+	msg, _ := proto.Marshal(&greet.GreetRequest{Greeting: &greet.Greeting{FirstName: "Jon"}})
+
+	// length prefixed message, flag 0 for data
+	frame := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(msg)))
+	copy(frame[5:], msg)
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/GreetService/GreetAlot", bytes.NewReader(frame))
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	req.Header.Set("Origin", "http://localhost:3000")
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+
+	if res.Header.Get("Access-Control-Allow-Origin") != "http://localhost:3000" {
+		t.Errorf("missing CORS header, got %v", res.Header)
+	}
+
+	var got []string
+	var trailer string
+
+	for len(body) >= 5 {
+
+		n := binary.BigEndian.Uint32(body[1:5])
+		data := body[5 : 5+n]
+
+		if body[0]&0x80 != 0 {
+			trailer = string(data)
+		} else {
+			r := new(greet.GreetResponse)
+			proto.Unmarshal(data, r)
+			got = append(got, r.GetResponse())
+		}
+
+		body = body[5+n:]
+	}
+
+	if strings.Join(got, "") != "Jon" || !strings.Contains(trailer, "grpc-status: 0") {
+		t.Errorf("unexpected stream %v with trailer %q", got, trailer)
+	}
+}
+
+func TestGRPCWebCORS(t *testing.T) {
+
+	gs := grpc.NewServer()
+	greet.RegisterGreetServiceServer(gs, &greeter{})
+
+	handler := GRPCWeb(gs, []string{"http://localhost:3000"}, http.NotFoundHandler())
+
+	for origin, allowed := range map[string]bool{"http://localhost:3000": true, "http://evil.example": false} {
+
+		req := httptest.NewRequest(http.MethodOptions, "/GreetService/GreetAlot", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-grpc-web,authorization")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin") == origin; got != allowed {
+			t.Errorf("origin %v allowed = %v, want %v", origin, got, allowed)
+		}
+	}
+}
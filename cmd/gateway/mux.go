@@ -0,0 +1,59 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// SinglePort - routes every protocol arriving on one listener: HTTP/2 gRPC
+// to gs, gRPC-Web to gs through the gRPC-Web wrapper and anything else
+// (REST/JSON, metrics, health) to rest. Serve it over TLS with h2 in
+// NextProtos, or wrapped with h2c for clear text HTTP/2 in development.
+func SinglePort(gs *grpc.Server, origins []string, rest http.Handler) http.Handler {
+
+	web := GRPCWeb(gs, origins, rest)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		if isGRPC(r) {
+			gs.ServeHTTP(w, r)
+			return
+		}
+
+		web.ServeHTTP(w, r)
+	})
+}
+
+// isGRPC - native gRPC requests are HTTP/2 with an application/grpc content type
+// (gRPC-Web uses application/grpc-web)
+func isGRPC(r *http.Request) bool {
+
+	ct := r.Header.Get("Content-Type")
+
+	return r.ProtoMajor == 2 && strings.HasPrefix(ct, "application/grpc") && !strings.HasPrefix(ct, "application/grpc-web")
+}
+
+// Healthz - plain HTTP readiness probe over the gRPC health service.
+// GET /healthz checks the whole server, /healthz?service=BlogService one service.
+func Healthz(hs healthpb.HealthServer) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		res, err := hs.Check(r.Context(), &healthpb.HealthCheckRequest{Service: r.URL.Query().Get("service")})
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			http.Error(w, res.GetStatus().String(), http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(res.GetStatus().String() + "\n"))
+	})
+}
@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthz(t *testing.T) {
+
+	hs := health.NewServer()
+	hs.SetServingStatus("BlogService", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	for target, want := range map[string]int{
+		"/healthz":                       http.StatusOK,
+		"/healthz?service=BlogService":   http.StatusServiceUnavailable,
+		"/healthz?service=NoSuchService": http.StatusNotFound,
+	} {
+
+		w := httptest.NewRecorder()
+		Healthz(hs).ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+
+		if w.Code != want {
+			t.Errorf("%v returned %v, want %v", target, w.Code, want)
+		}
+	}
+}
+
+func TestIsGRPC(t *testing.T) {
+
+	for ct, want := range map[string]bool{
+		"application/grpc":           true,
+		"application/grpc+proto":     true,
+		"application/grpc-web+proto": false,
+		"application/json":           false,
+	} {
+
+		r := httptest.NewRequest(http.MethodPost, "/GreetService/Greet", nil)
+		r.ProtoMajor = 2
+		r.Header.Set("Content-Type", ct)
+
+		if isGRPC(r) != want {
+			t.Errorf("isGRPC(%v) = %v, want %v", ct, !want, want)
+		}
+	}
+}
@@ -3,16 +3,45 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"grpcourse/cmd/admin"
+	"grpcourse/cmd/gateway"
+	"grpcourse/cmd/listen"
+	"grpcourse/cmd/metrics"
+	"grpcourse/cmd/middleware"
+	"grpcourse/cmd/pki"
 	"grpcourse/cmd/server"
+	"grpcourse/cmd/tracing"
+	"grpcourse/config"
+	"grpcourse/data/cache"
+	"grpcourse/data/db"
+	"grpcourse/data/media"
+	"grpcourse/data/migrate"
+	auditpb "grpcourse/data/protos/audit"
 	blogpb "grpcourse/data/protos/blog"
 	"grpcourse/data/protos/greet"
+	"grpcourse/data/store"
+	"grpcourse/data/tenant"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	awscreds "github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/go-redis/redis/v7"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -25,7 +54,12 @@ var grpcServer = &cobra.Command{
 	},
 }
 
+// storeBackend - --store, overrides store.backend
+var storeBackend string
+
 func init() {
+	grpcServer.Flags().StringVar(&storeBackend, "store", "", "where blogs are kept : mongo or bolt (store.path, no mongo needed)")
+
 	rootCmd.AddCommand(grpcServer)
 }
 
@@ -35,26 +69,115 @@ func serve() {
 	fmt.Println("Starting gRPC server ...")
 
 	// 1. Server instance
-	svr := server.NewServer()
+	if storeBackend != "" {
+		cfg.Store.Backend = storeBackend
+	}
+
+	if err := cfg.Store.Check(cfg.Tenancy); err != nil {
+		logrus.Fatalf("%v", err)
+	}
+
+	st, err := openStore(cfg.Store)
+
+	if err != nil {
+		logrus.Fatalf("cannot open the %v store : %v", cfg.Store.Backend, err)
+	}
+
+	svr := server.NewServer(cfg, st)
+
+	if svr.Images, err = openImages(cfg.Images); err != nil {
+		logrus.Fatalf("cannot open the %v image store : %v", cfg.Images.Backend, err)
+	}
+
+	auth := middleware.NewAuthenticator(cfg.Auth)
+
+	peers := middleware.NewPeerAuthorizer(cfg.TLS)
+
+	limiter := middleware.NewRateLimiter(cfg.RateLimit)
+
+	// each team's blogs in its own database, tenants picked after authentication
+	var known func(context.Context, string) (bool, error)
+
+	if cfg.Tenancy.Enabled {
+		registry := tenant.NewRegistry(db.GetDB(), cfg.Tenancy.DatabasePrefix)
+		known = registry.Exists
+
+		svr.TenantStore = func(id string) store.Store {
+			return store.NewMongo(registry.Database(id))
+		}
+	}
+
+	tenants := middleware.NewTenantResolver(cfg.Tenancy, known)
+
+	// adaptive limits per method group, exported as gauges
+	shedder := middleware.NewConcurrencyLimiter(cfg.Concurrency)
+	shedder.OnLimit, shedder.OnShed = metrics.ObserveLimit, metrics.ObserveShed
+
+	for group, limit := range shedder.Limits() {
+		metrics.ObserveLimit(group, limit)
+	}
+
+	// spans for every RPC, joined to the caller's trace through grpc metadata
+	shutdownTracing, err := tracing.Setup(cfg.Tracing, "grpcourse-server")
+
+	if err != nil {
+		svr.Logger.Fatalf("cannot set up tracing : %v", err)
+	}
+
+	// calls in progress, waited for on shutdown
+	inflight := middleware.NewTracker()
+
+	// before any grpc.Server is created, channelz only tracks servers created after it is on
+	var adm *admin.Admin
+
+	if cfg.Admin.Addr != "" {
+		adm = admin.New(auth, inflight, svr.Logger)
+	}
+
+	// interceptors run before every handler - tracing and metrics first so rejected calls are recorded
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(inflight.Unary(), middleware.UnaryRequestID(), otelgrpc.UnaryServerInterceptor(), metrics.UnaryInterceptor(), shedder.Unary(), peers.Unary(), auth.Unary(), tenants.Unary(), limiter.Unary(), middleware.UnaryValidator()),
+		grpc.ChainStreamInterceptor(inflight.Stream(), middleware.StreamRequestID(), otelgrpc.StreamServerInterceptor(), metrics.StreamInterceptor(), shedder.Stream(), peers.Stream(), auth.Stream(), tenants.Stream(), limiter.Stream(), middleware.StreamValidator()),
+	}
+
+	// mongo command latency per collection
+	db.SetObserver(metrics.ObserveMongo)
+
+	// blogs and the blog list are served through the cache, hits and misses counted
+	cache.SetObserver(metrics.ObserveCache)
+	svr.UseCache(newCache(cfg.Cache, svr.Logger), time.Duration(cfg.Cache.BlogTTL), time.Duration(cfg.Cache.ListTTL))
 
-	tls := true
+	// stops background work (certificate watcher) on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	opts := []grpc.ServerOption{}
+	var reloader *pki.Reloader
 
-	if tls {
+	if cfg.TLS.Enabled {
 
-		// cert and pem - pass full path
-		cert := "ssl/server.crt"
-		pem := "ssl/server.pem"
+		var cred credentials.TransportCredentials
 
-		cred, err := credentials.NewServerTLSFromFile(cert, pem)
+		// cert and pem - paths come from config, mutual TLS when a client CA is set.
+		// never fall back to plain text when TLS was asked for
+		cred, reloader, err = serverCredentials(cfg.TLS, svr.Logger)
 
 		if err != nil {
-			svr.Logger.Errorf("cannot creat tls cert from file : %v", err)
+			svr.Logger.Fatalf("cannot create tls credentials : %v", err)
 		}
 
+		// expiry gauge follows every reload
+		reloader.OnLoad = metrics.ObserveCertificate
+		metrics.ObserveCertificate(reloader.Leaf())
+
+		// pick up rotated certificates - on change and on SIGHUP
+		if cfg.TLS.ReloadInterval > 0 {
+			go reloader.Watch(ctx, time.Duration(cfg.TLS.ReloadInterval))
+		}
+
+		go reloadOnHangup(ctx, reloader, svr.Logger)
+
 		// gRPC server with opts
-		opts = append(opts, grpc.Creds(cred))
+		opts = append(opts, grpc.Creds(listenerCredentials{cred}))
 	}
 
 	// 2. instantiate grpc server
@@ -69,35 +192,536 @@ func serve() {
 
 	blogpb.RegisterBlogServiceServer(gs, svr)
 
-	// 4. create a tcp listener
-	lis, err := net.Listen("tcp", ":50051")
+	// admins read the trail of blog mutations
+	auditpb.RegisterAuditServiceServer(gs, svr)
+
+	// standard health checking - BlogService follows mongo, GreetService is independent
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(gs, hs)
+
+	go svr.WatchHealth(ctx, hs, time.Duration(cfg.Health.Interval), time.Duration(cfg.Health.Timeout))
+
+	// indexes follow the code, migrations are left to the migrate command
+	if cfg.Store.Backend == "mongo" {
+		go checkSchema(ctx, svr.Logger)
+	}
+
+	// 4. one port for everything, or gRPC only with the HTTP endpoints on their own ports
+	var httpSrv *http.Server
+
+	if cfg.Server.SinglePort {
+
+		if httpSrv, err = singlePortServer(ctx, gs, hs, reloader, svr.Logger); err != nil {
+			svr.Logger.Fatalf("cannot serve on a single port : %v", err)
+		}
+	}
+
+	// 5. every listener serves everything, TLS is decided per listener
+	var listeners []net.Listener
+
+	for _, l := range cfg.Server.Listeners {
+
+		lis, err := listen.Listen(l)
+
+		if err != nil {
+			svr.Logger.Fatalf("could not get listener %v %v : %v", l.Network, l.Addr, err)
+		}
+
+		listeners = append(listeners, lis)
+
+		secure := l.Secure(cfg.TLS)
+
+		svr.Logger.Infof("listening on %v %v (tls %v)", l.Network, lis.Addr(), secure)
+
+		go func(lis net.Listener) {
+
+			var err error
+
+			switch {
+			case httpSrv != nil && secure:
+				err = httpSrv.ServeTLS(lis, "", "")
+
+			case httpSrv != nil:
+				err = httpSrv.Serve(lis)
+
+			case cfg.TLS.Enabled && !secure:
+				err = gs.Serve(plainListener{lis})
+
+			default:
+				err = gs.Serve(lis)
+			}
+
+			if err != nil && err != http.ErrServerClosed {
+				svr.Logger.Fatalf("gRPC server couldn't listen to port: %v", err)
+			}
+		}(lis)
+	}
+
+	// prometheus endpoint
+	var metricsSrv *http.Server
+
+	if cfg.Metrics.Addr != "" {
+
+		metricsSrv = &http.Server{Addr: cfg.Metrics.Addr, Handler: metrics.Handler()}
+
+		go func() {
+
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				svr.Logger.Errorf("metrics endpoint stopped : %v", err)
+			}
+		}()
+	}
+
+	// REST/JSON gateway proxying to the listener above
+	var gatewaySrv *http.Server
+
+	if cfg.Gateway.Addr != "" {
+
+		if gatewaySrv, err = startGateway(ctx, reloader, svr.Logger); err != nil {
+			svr.Logger.Fatalf("cannot start gateway : %v", err)
+		}
+	}
+
+	// gRPC-Web for browsers - same server, so the same interceptors apply
+	var webSrv *http.Server
+
+	if cfg.GRPCWeb.Addr != "" {
+
+		if webSrv, err = startGRPCWeb(gs, reloader, svr.Logger); err != nil {
+			svr.Logger.Fatalf("cannot start gRPC-Web : %v", err)
+		}
+	}
+
+	// operators - kept up while draining so /rpcs shows what is left
+	var adminSrv *http.Server
+
+	if adm != nil {
+
+		if adminSrv, err = startAdmin(adm, reloader, svr.Logger); err != nil {
+			svr.Logger.Fatalf("cannot start admin endpoint : %v", err)
+		}
+	}
+
+	// Gracefully shut down the grpc server
+	fmt.Println("[ EXIT ] Press CTRL + C ...")
+
+	kill := make(chan os.Signal, 1)
+	signal.Notify(kill, os.Interrupt, syscall.SIGTERM)
+
+	sig := <-kill
+
+	svr.Logger.Infof("%v received, draining for up to %v....", sig, time.Duration(cfg.Server.DrainTimeout))
+
+	// load balancers and orchestrators stop sending traffic, WatchHealth can no longer flip it back
+	hs.Shutdown()
+
+	drainCtx, stopDrain := context.WithTimeout(context.Background(), time.Duration(cfg.Server.DrainTimeout))
+
+	// a second signal cuts the drain short
+	go func() {
+
+		select {
+		case <-kill:
+			svr.Logger.Warnf("second signal received, stopping now")
+			stopDrain()
+
+		case <-drainCtx.Done():
+		}
+	}()
+
+	code := (&running{
+		svr:      svr,
+		gs:       gs,
+		inflight: inflight,
+		native:   httpSrv == nil,
+		drained:  []*http.Server{gatewaySrv, webSrv, httpSrv},
+		closed:   []*http.Server{metricsSrv, adminSrv},
+		stop: []func(){func() {
+			if adm != nil {
+				adm.Stop()
+			}
+		}, cancel},
+		flush: shutdownTracing,
+	}).shutdown(drainCtx)
+
+	stopDrain()
+
+	os.Exit(code)
+}
+
+// running - what serve takes down on shutdown, in order
+type running struct {
+	svr      *server.Server
+	gs       *grpc.Server
+	inflight *middleware.Tracker
+
+	// native - gs has listeners of its own, not only ServeHTTP
+	native bool
+
+	// drained - feed calls into gs and are drained with it, closed - the rest
+	drained []*http.Server
+	closed  []*http.Server
+
+	// stop - background work ended once gs stopped
+	stop []func()
+
+	// flush - exports the traces still buffered
+	flush func(context.Context) error
+}
+
+// shutdown - drains until ctx is done, cuts off whatever is left and closes
+// the store last. Returns the exit code, 1 when calls had to be cancelled.
+func (r *running) shutdown(ctx context.Context) int {
+
+	code := 0
+
+	if !drain(ctx, r.gs, r.inflight, r.native, r.drained...) {
+
+		for _, c := range r.inflight.Active() {
+			r.svr.Logger.Warnf("cancelling %v from %v, running for %v", c.Method, c.Peer, time.Since(c.Started).Round(time.Millisecond))
+		}
+
+		code = 1
+	}
+
+	// cuts off whatever is left - a no-op after a clean drain
+	for _, srv := range append(r.drained, r.closed...) {
+		if srv != nil {
+			srv.Close()
+		}
+	}
+
+	r.gs.Stop()
+
+	for _, stop := range r.stop {
+		stop()
+	}
+
+	flushCtx, stopFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stopFlush()
+
+	if err := r.flush(flushCtx); err != nil {
+		r.svr.Logger.Errorf("cannot flush traces : %v", err)
+	}
+
+	// last - handlers still running above may need it
+	r.svr.Logger.Printf("Closing the %v store....", r.svr.Config.Store.Backend)
+
+	if err := r.svr.Store.Close(flushCtx); err != nil {
+		r.svr.Logger.Errorf("cannot close the store : %v", err)
+	}
+
+	if code != 0 {
+		r.svr.Logger.Errorf("drain deadline passed, in-flight calls were cancelled")
+	} else {
+		r.svr.Logger.Println("Server stopped")
+	}
+
+	return code
+}
+
+// drain - stops accepting calls and waits for those in flight until ctx is
+// done, reporting whether everything finished in time. The HTTP servers go
+// first: they feed calls into gs through ServeHTTP, which GracefulStop cannot
+// drain. native is false when gs is only served through ServeHTTP.
+func drain(ctx context.Context, gs *grpc.Server, inflight *middleware.Tracker, native bool, servers ...*http.Server) bool {
+
+	for _, srv := range servers {
+
+		if srv == nil {
+			continue
+		}
+
+		if err := srv.Shutdown(ctx); err != nil {
+			return false
+		}
+	}
+
+	if native {
+
+		done := make(chan struct{})
+
+		go func() {
+			gs.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	// h2c connections are hijacked from the HTTP server, Shutdown does not wait for them
+	return inflight.Wait(ctx) == nil
+}
+
+// newCache - the backend selected in config, nil for none. An unreachable
+// redis is only logged: reads go to mongo until it comes back.
+func newCache(c config.Cache, logger *logrus.Logger) cache.Cache {
+
+	switch c.Backend {
+	case "memory":
+		return cache.NewLRU(c.Size)
+
+	case "redis":
+
+		client := redis.NewClient(&redis.Options{Addr: c.Redis.Addr, Password: c.Redis.Password, DB: c.Redis.DB})
+
+		if err := client.Ping().Err(); err != nil {
+			logger.Warnf("redis cache at %v unreachable : %v", c.Redis.Addr, err)
+		}
+
+		return cache.NewRedis(client, c.Redis.Prefix)
+	}
+
+	return nil
+}
+
+// reloadOnHangup - reloads the server certificate whenever the process receives SIGHUP
+func reloadOnHangup(ctx context.Context, reloader *pki.Reloader, logger *logrus.Logger) {
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hup:
+
+			logger.Infof("SIGHUP received, reloading certificate")
+
+			if err := reloader.Reload(); err != nil {
+				logger.Errorf("certificate reload failed, keeping the current one : %v", err)
+			}
+		}
+	}
+}
+
+// singlePortServer - serves gRPC, gRPC-Web, the REST gateway and /healthz on
+// the same listeners. /metrics stays on its own address, out of reach of
+// the clients of the API. Over TLS, ALPN picks HTTP/2 for gRPC
+// clients and HTTP/1.1 for the rest, plaintext listeners accept clear text
+// HTTP/2 (h2c).
+func singlePortServer(ctx context.Context, gs *grpc.Server, hs *health.Server, reloader *pki.Reloader, logger *logrus.Logger) (*http.Server, error) {
+
+	rest, err := gatewayHandler(ctx, logger)
 
 	if err != nil {
-		svr.Logger.Fatalf("could not get listener : %v", err)
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", gateway.Healthz(hs))
+	mux.Handle("/", rest)
+
+	h2s := &http2.Server{}
+
+	srv := &http.Server{
+		Handler: h2c.NewHandler(gateway.SinglePort(gs, cfg.GRPCWeb.AllowedOrigins, mux), h2s),
+
+		// callers on a unix socket are trusted without a client certificate
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+
+			if c.LocalAddr().Network() == listen.Unix {
+				return middleware.WithLocal(ctx)
+			}
+
+			return ctx
+		},
+	}
+
+	if reloader != nil {
+
+		conf, err := serverTLSConfig(cfg.TLS, reloader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		conf.NextProtos = []string{"h2", "http/1.1"}
+
+		srv.TLSConfig = conf
+	}
+
+	// Shutdown sends GOAWAY to the HTTP/2 connections, h2c ones included
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return nil, err
+	}
+
+	return srv, nil
+}
+
+// startGRPCWeb - serves gs to gRPC-Web clients on cfg.GRPCWeb.Addr. With TLS
+// on, the listener uses the gRPC server certificate and client CA.
+func startGRPCWeb(gs *grpc.Server, reloader *pki.Reloader, logger *logrus.Logger) (*http.Server, error) {
+
+	c := cfg.GRPCWeb
+
+	srv := &http.Server{Addr: c.Addr, Handler: gateway.GRPCWeb(gs, c.AllowedOrigins, http.NotFoundHandler())}
+
+	if c.TLS {
+
+		if reloader == nil {
+			return nil, fmt.Errorf("grpc_web.tls needs tls.enabled")
+		}
+
+		conf, err := serverTLSConfig(cfg.TLS, reloader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = conf
 	}
 
 	go func() {
 
-		if err := gs.Serve(lis); err != nil {
-			svr.Logger.Fatalf("gRPC server couldn't listen to port: %v", err)
+		logger.Infof("gRPC-Web listening on %v", c.Addr)
+
+		var err error
+
+		if c.TLS {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorf("gRPC-Web stopped : %v", err)
 		}
 	}()
 
-	// Gracefully shut down the grpc server
-	fmt.Println("[ EXIT ] Press CTRL + C ...")
+	return srv, nil
+}
+
+// startAdmin - serves adm on cfg.Admin.Addr, HTTP/2 is negotiated over TLS
+// and accepted in clear text so gRPC clients reach channelz
+func startAdmin(adm *admin.Admin, reloader *pki.Reloader, logger *logrus.Logger) (*http.Server, error) {
+
+	c := cfg.Admin
+
+	h2s := &http2.Server{}
+
+	srv := &http.Server{Addr: c.Addr, Handler: h2c.NewHandler(adm.Handler(), h2s)}
+
+	if c.TLS {
+
+		if reloader == nil {
+			return nil, fmt.Errorf("admin.tls needs tls.enabled")
+		}
+
+		conf, err := serverTLSConfig(cfg.TLS, reloader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		conf.NextProtos = []string{"h2", "http/1.1"}
+
+		srv.TLSConfig = conf
+	}
+
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return nil, err
+	}
+
+	go func() {
+
+		logger.Infof("admin endpoint listening on %v", c.Addr)
+
+		var err error
+
+		if c.TLS {
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
 
-	kill := make(chan os.Signal)
-	signal.Notify(kill, os.Interrupt)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorf("admin endpoint stopped : %v", err)
+		}
+	}()
 
-	<-kill
+	return srv, nil
+}
 
-	svr.Logger.Println("Server gracefully shutting down....")
+// openStore - the blog store selected by c
+func openStore(c config.Store) (store.Store, error) {
 
-	svr.Logger.Println("Closing mongodb connection....")
+	if c.Backend == "bolt" {
+		return store.NewBolt(c.Path)
+	}
 
-	svr.DB.Client().Disconnect(context.TODO())
+	return store.NewMongo(db.GetDB()), nil
+}
 
-	lis.Close()
+// openImages - the image store selected by c
+func openImages(c config.Images) (media.Store, error) {
+
+	switch c.Backend {
+	case "gridfs":
+		return media.NewGridFS(db.GetDB(), c.Bucket)
+
+	case "s3":
+
+		conf := &aws.Config{
+			Region:           aws.String(c.S3.Region),
+			S3ForcePathStyle: aws.Bool(c.S3.PathStyle),
+		}
+
+		if c.S3.Endpoint != "" {
+			conf.Endpoint = aws.String(c.S3.Endpoint)
+		}
+
+		if c.S3.AccessKey != "" {
+			conf.Credentials = awscreds.NewStaticCredentials(c.S3.AccessKey, c.S3.SecretKey, "")
+		}
+
+		sess, err := session.NewSession(conf)
+
+		if err != nil {
+			return nil, err
+		}
 
-	os.Exit(0)
+		s := media.NewS3(s3.New(sess), c.S3.Bucket, c.S3.Prefix, c.S3.PartSize)
+		s.Checksums = c.S3.Checksums
+
+		return s, nil
+	}
+
+	return media.NewDisk(c.Dir), nil
+}
+
+// checkSchema - creates missing indexes in every blog database and warns
+// about pending migrations
+func checkSchema(ctx context.Context, logger *logrus.Logger) {
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	databases, err := blogDatabases(ctx)
+
+	if err != nil {
+		logger.Errorf("cannot check the schema : %v", err)
+		return
+	}
+
+	for _, d := range databases {
+
+		if err := ensureIndexes(ctx, d, logger); err != nil {
+			logger.Errorf("%v : %v", d.Name(), err)
+		}
+
+		if n, err := migrate.Pending(ctx, d, migrate.Migrations); err != nil {
+			logger.Errorf("%v : cannot read schema_migrations : %v", d.Name(), err)
+		} else if n > 0 {
+			logger.Warnf("%v : %d migrations pending, run the migrate up command", d.Name(), n)
+		}
+	}
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+	"grpcourse/cmd/server"
+	"grpcourse/config"
+	"grpcourse/data/protos/greet"
+	"grpcourse/data/store"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// closeStore - records that the store was closed
+type closeStore struct {
+	store.Store
+	closed bool
+}
+
+func (s *closeStore) Close(ctx context.Context) error {
+	s.closed = true
+	return s.Store.Close(ctx)
+}
+
+func TestShutdownCutsOffStreams(t *testing.T) {
+
+	bolt, err := store.NewBolt(filepath.Join(t.TempDir(), "blogs.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &closeStore{Store: bolt}
+
+	svr := server.NewServer(config.Default(), st)
+	svr.Logger.SetLevel(logrus.PanicLevel)
+
+	inflight := middleware.NewTracker()
+
+	gs := grpc.NewServer(grpc.ChainStreamInterceptor(inflight.Stream()))
+	greet.RegisterGreetServiceServer(gs, svr)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go gs.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	// a stream the client keeps open past the drain deadline
+	stream, err := greet.NewGreetServiceClient(conn).GreetEveryone(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream.Send(&greet.GreetRequest{Greeting: &greet.Greeting{FirstName: "Ada"}})
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("cannot greet : %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	r := &running{
+		svr:      svr,
+		gs:       gs,
+		inflight: inflight,
+		native:   true,
+		flush:    func(context.Context) error { return nil },
+	}
+
+	if code := r.shutdown(ctx); code != 1 {
+		t.Errorf("expected exit code 1 after cancelling the stream, got %v", code)
+	}
+
+	if !st.closed {
+		t.Errorf("the store was not closed")
+	}
+
+	if _, err := stream.Recv(); err == nil {
+		t.Errorf("expected the stream to be cut off")
+	}
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/cmd/server"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var healthCmd = &cobra.Command{
+	Use:   "health [service...]",
+	Short: "Query the server health statuses (exits non-zero unless all are SERVING)",
+	// probes only care about the exit code, main prints the error
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		services := args
+
+		if len(services) == 0 {
+			services = []string{"", server.GreetServiceName, server.BlogServiceName}
+		}
+
+		conn := dial()
+		defer conn.Close()
+
+		hc := healthpb.NewHealthClient(conn)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(w, "SERVICE\tSTATUS")
+
+		healthy := true
+
+		for _, svc := range services {
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+			res, err := hc.Check(ctx, &healthpb.HealthCheckRequest{Service: svc})
+
+			cancel()
+
+			name := svc
+
+			if name == "" {
+				name = "(server)"
+			}
+
+			if err != nil {
+				healthy = false
+				fmt.Fprintf(w, "%v\t%v\n", name, err)
+				continue
+			}
+
+			if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				healthy = false
+			}
+
+			fmt.Fprintf(w, "%v\t%v\n", name, res.GetStatus())
+		}
+
+		w.Flush()
+
+		if !healthy {
+			return fmt.Errorf("server is not healthy")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	grpcClient.AddCommand(healthCmd)
+}
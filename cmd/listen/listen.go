@@ -0,0 +1,155 @@
+package listen
+
+import (
+	"fmt"
+	"grpcourse/config"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// Networks accepted in config.Listener
+const (
+	TCP  = "tcp"
+	Unix = "unix"
+	FD   = "fd"
+)
+
+// first file descriptor passed by systemd socket activation
+const listenFDsStart = 3
+
+// Listen - opens the listener described by l
+func Listen(l config.Listener) (net.Listener, error) {
+
+	switch l.Network {
+	case TCP, "":
+		return net.Listen("tcp", l.Addr)
+
+	case Unix:
+		return listenUnix(l)
+
+	case FD:
+		return inherited(l.Addr)
+	}
+
+	return nil, fmt.Errorf("unknown listener network %q (want %v, %v or %v)", l.Network, TCP, Unix, FD)
+}
+
+// listenUnix - creates the socket file with the configured mode and owner.
+// A stale socket left by a previous run is removed first.
+func listenUnix(l config.Listener) (net.Listener, error) {
+
+	if fi, err := os.Stat(l.Addr); err == nil {
+
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%v exists and is not a socket", l.Addr)
+		}
+
+		if err := os.Remove(l.Addr); err != nil {
+			return nil, err
+		}
+	}
+
+	lis, err := net.Listen("unix", l.Addr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setPermissions(l); err != nil {
+		lis.Close()
+		return nil, err
+	}
+
+	return lis, nil
+}
+
+// setPermissions - applies the listener mode, owner and group to its socket file
+func setPermissions(l config.Listener) error {
+
+	if l.Mode != "" {
+
+		mode, err := strconv.ParseUint(l.Mode, 8, 32)
+
+		if err != nil {
+			return fmt.Errorf("invalid socket mode %q : %v", l.Mode, err)
+		}
+
+		if err := os.Chmod(l.Addr, os.FileMode(mode)); err != nil {
+			return err
+		}
+	}
+
+	if l.Owner == "" && l.Group == "" {
+		return nil
+	}
+
+	uid, gid := -1, -1
+
+	if l.Owner != "" {
+
+		u, err := user.Lookup(l.Owner)
+
+		if err != nil {
+			if u, err = user.LookupId(l.Owner); err != nil {
+				return fmt.Errorf("unknown socket owner %q", l.Owner)
+			}
+		}
+
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+
+	if l.Group != "" {
+
+		g, err := user.LookupGroup(l.Group)
+
+		if err != nil {
+			if g, err = user.LookupGroupId(l.Group); err != nil {
+				return fmt.Errorf("unknown socket group %q", l.Group)
+			}
+		}
+
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+
+	return os.Lchown(l.Addr, uid, gid)
+}
+
+// inherited - a socket passed by systemd socket activation (LISTEN_FDS).
+// name is the descriptor number or its FileDescriptorName= from the unit.
+func inherited(name string) (net.Listener, error) {
+
+	if pid, _ := strconv.Atoi(os.Getenv("LISTEN_PID")); pid != os.Getpid() {
+		return nil, fmt.Errorf("no sockets were passed to this process (LISTEN_PID)")
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("no sockets were passed to this process (LISTEN_FDS)")
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	fd := -1
+
+	for i := 0; i < count; i++ {
+
+		if strconv.Itoa(listenFDsStart+i) == name || (i < len(names) && names[i] == name) {
+			fd = listenFDsStart + i
+			break
+		}
+	}
+
+	if fd < 0 {
+		return nil, fmt.Errorf("no inherited socket named %q", name)
+	}
+
+	file := os.NewFile(uintptr(fd), name)
+	defer file.Close()
+
+	// net.FileListener dups the descriptor
+	return net.FileListener(file)
+}
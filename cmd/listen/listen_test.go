@@ -0,0 +1,87 @@
+package listen
+
+import (
+	"grpcourse/config"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnixSocket(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "listen")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "grpc.sock")
+
+	l := config.Listener{Network: Unix, Addr: path, Mode: "0600"}
+
+	// a socket left behind by a crashed process is replaced
+	stale, err := net.Listen("unix", path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	lis, err := Listen(l)
+
+	if err != nil {
+		t.Fatalf("cannot listen on %v : %v", path, err)
+	}
+
+	defer lis.Close()
+
+	fi, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("socket mode is %v, want 0600", fi.Mode().Perm())
+	}
+
+	conn, err := net.Dial("unix", path)
+
+	if err != nil {
+		t.Fatalf("cannot connect : %v", err)
+	}
+
+	conn.Close()
+}
+
+func TestListenErrors(t *testing.T) {
+
+	file, err := ioutil.TempFile("", "listen")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+	defer os.Remove(file.Name())
+
+	os.Unsetenv("LISTEN_PID")
+
+	for name, l := range map[string]config.Listener{
+		"regular file":    {Network: Unix, Addr: file.Name()},
+		"no systemd":      {Network: FD, Addr: "3"},
+		"unknown network": {Network: "udp", Addr: ":50051"},
+	} {
+
+		if lis, err := Listen(l); err == nil {
+			lis.Close()
+			t.Errorf("%s : expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryInterceptor - counts and times unary RPCs. It should be the first
+// interceptor so calls rejected by the others are recorded too.
+func UnaryInterceptor() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		service, method := splitMethod(info.FullMethod)
+
+		done := begin(service, method, "unary")
+
+		res, err := handler(ctx, req)
+
+		done(err)
+
+		return res, err
+	}
+}
+
+// StreamInterceptor - counts and times streaming RPCs and the messages they carry
+func StreamInterceptor() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		service, method := splitMethod(info.FullMethod)
+
+		done := begin(service, method, streamType(info))
+
+		err := handler(srv, &countingStream{
+			ServerStream: ss,
+			received:     StreamMessages.WithLabelValues(service, method, "received"),
+			sent:         StreamMessages.WithLabelValues(service, method, "sent"),
+		})
+
+		done(err)
+
+		return err
+	}
+}
+
+// begin - marks an RPC in flight, the returned func records its outcome
+func begin(service, method, typ string) func(error) {
+
+	start := time.Now()
+
+	inFlight := InFlight.WithLabelValues(service, method)
+	inFlight.Inc()
+
+	return func(err error) {
+
+		inFlight.Dec()
+
+		Latency.WithLabelValues(service, method, typ).Observe(time.Since(start).Seconds())
+		RPCs.WithLabelValues(service, method, typ, status.Code(err).String()).Inc()
+	}
+}
+
+// countingStream - server stream counting successfully received and sent messages
+type countingStream struct {
+	grpc.ServerStream
+	received, sent prometheus.Counter
+}
+
+// RecvMsg -
+func (s *countingStream) RecvMsg(m interface{}) error {
+
+	err := s.ServerStream.RecvMsg(m)
+
+	if err == nil {
+		s.received.Inc()
+	}
+
+	return err
+}
+
+// SendMsg -
+func (s *countingStream) SendMsg(m interface{}) error {
+
+	err := s.ServerStream.SendMsg(m)
+
+	if err == nil {
+		s.sent.Inc()
+	}
+
+	return err
+}
+
+// splitMethod - "/BlogService/CreateBlog" -> "BlogService", "CreateBlog"
+func splitMethod(full string) (string, string) {
+
+	full = strings.TrimPrefix(full, "/")
+
+	if i := strings.Index(full, "/"); i >= 0 {
+		return full[:i], full[i+1:]
+	}
+
+	return "unknown", full
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+
+	interceptor := UnaryInterceptor()
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/TestService/Fail"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+
+		if v := testutil.ToFloat64(InFlight.WithLabelValues("TestService", "Fail")); v != 1 {
+			t.Errorf("expected the call in flight, got %v", v)
+		}
+
+		return nil, status.Errorf(codes.NotFound, "missing")
+	})
+
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if v := testutil.ToFloat64(RPCs.WithLabelValues("TestService", "Fail", "unary", "NotFound")); v != 1 {
+		t.Errorf("expected one NotFound call, got %v", v)
+	}
+
+	if v := testutil.ToFloat64(InFlight.WithLabelValues("TestService", "Fail")); v != 0 {
+		t.Errorf("expected no calls in flight, got %v", v)
+	}
+}
+
+func TestSplitMethod(t *testing.T) {
+
+	if s, m := splitMethod("/BlogService/CreateBlog"); s != "BlogService" || m != "CreateBlog" {
+		t.Errorf("unexpected split %v %v", s, m)
+	}
+}
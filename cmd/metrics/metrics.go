@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"crypto/x509"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "grpcourse"
+
+var (
+	// RPCs - finished RPCs by method and status code
+	RPCs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "RPCs completed on the server by method and status code.",
+	}, []string{"service", "method", "type", "code"})
+
+	// Latency - RPC handling time, streams included
+	Latency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Time taken to handle RPCs by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"service", "method", "type"})
+
+	// InFlight - RPCs currently being handled
+	InFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "rpc_in_flight",
+		Help:      "RPCs currently being handled by method.",
+	}, []string{"service", "method"})
+
+	// StreamMessages - messages received and sent on streaming RPCs
+	StreamMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stream_messages_total",
+		Help:      "Stream messages by method and direction (received, sent).",
+	}, []string{"service", "method", "direction"})
+
+	// UploadBytes - image bytes received by CreateBlog
+	UploadBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blog_upload_bytes_total",
+		Help:      "Image bytes received by CreateBlog uploads.",
+	})
+
+	// UploadsRejected - CreateBlog uploads refused for being over the size limit or not an image
+	UploadsRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "blog_upload_rejected_total",
+		Help:      "CreateBlog uploads rejected for exceeding the image size limit or not being an image.",
+	})
+
+	// MongoLatency - mongo command time by collection and command
+	MongoLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "MongoDB command latency by collection, command and outcome.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "command", "outcome"})
+
+	// CacheRequests - blog cache lookups by cache and outcome
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Blog cache lookups by cache (blog, list) and result (hit, miss, error).",
+	}, []string{"cache", "result"})
+
+	// ConcurrencyLimit - current adaptive limit of each method group
+	ConcurrencyLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "concurrency_limit",
+		Help:      "Calls allowed in progress at once by method group.",
+	}, []string{"group"})
+
+	// Shed - calls turned away by the concurrency limit
+	Shed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_shed_total",
+		Help:      "Calls rejected with UNAVAILABLE because their group was at its concurrency limit.",
+	}, []string{"group"})
+
+	// CertificateExpiry - NotAfter of the certificate the server is presenting
+	CertificateExpiry = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tls_certificate_expiry_timestamp_seconds",
+		Help:      "Unix time at which the served TLS certificate expires.",
+	})
+)
+
+// Handler - serves the registered metrics in the prometheus text format
+func Handler() http.Handler {
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return mux
+}
+
+// ObserveMongo - records a finished mongo command (see db.SetObserver)
+func ObserveMongo(collection, command string, took time.Duration, failed bool) {
+
+	outcome := "success"
+
+	if failed {
+		outcome = "failure"
+	}
+
+	MongoLatency.WithLabelValues(collection, command, outcome).Observe(took.Seconds())
+}
+
+// ObserveCertificate - records the expiry of a (re)loaded certificate (see pki.Reloader.OnLoad)
+func ObserveCertificate(leaf *x509.Certificate) {
+	CertificateExpiry.Set(float64(leaf.NotAfter.Unix()))
+}
+
+// ObserveLimit - records a concurrency limit change (see middleware.ConcurrencyLimiter.OnLimit)
+func ObserveLimit(group string, limit int) {
+	ConcurrencyLimit.WithLabelValues(group).Set(float64(limit))
+}
+
+// ObserveShed - counts a shed call (see middleware.ConcurrencyLimiter.OnShed)
+func ObserveShed(group string) {
+	Shed.WithLabelValues(group).Inc()
+}
+
+// ObserveCache - counts a cache lookup (see cache.SetObserver)
+func ObserveCache(name, result string) {
+	CacheRequests.WithLabelValues(name, result).Inc()
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/config"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Identity - the authenticated caller of an RPC
+type Identity struct {
+	// Subject - `sub` claim, used as the blog author id. Only set from a
+	// verified token.
+	Subject string
+
+	// Admin - caller may act on resources owned by others
+	Admin bool
+
+	// Certificate - identity of the verified client certificate, empty without mutual TLS
+	Certificate string
+
+	// Tenant - `tenant` claim, the only tenant the token may act in. Empty
+	// for certificate identities and tokens not bound to a tenant.
+	Tenant string
+}
+
+type identityKey struct{}
+
+// WithIdentity - returns a copy of ctx carrying id
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext - returns the caller identity attached by the auth interceptor
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+
+	return id, ok && id != nil
+}
+
+// Claims - JWT claims understood by the server
+type Claims struct {
+	Admin  bool   `json:"admin,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	jwt.StandardClaims
+}
+
+// Authenticator - validates bearer tokens sent in the `authorization` metadata
+type Authenticator struct {
+	cfg config.Auth
+}
+
+// NewAuthenticator - returns an Authenticator for the given settings
+func NewAuthenticator(cfg config.Auth) *Authenticator {
+	return &Authenticator{cfg: cfg}
+}
+
+// Unary - unary server interceptor
+func (a *Authenticator) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - stream server interceptor
+func (a *Authenticator) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticate - attaches the token identity to ctx. A token is optional on
+// public methods only, but must still be valid when one is sent. A client
+// certificate does not stand in for a token.
+func (a *Authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+
+	if !a.cfg.Enabled {
+		return ctx, nil
+	}
+
+	token, err := bearerToken(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	peerID, hasPeer := IdentityFromContext(ctx)
+
+	if token == "" {
+
+		if matchMethod(a.cfg.Public, method) {
+			return ctx, nil
+		}
+
+		return nil, status.Errorf(codes.Unauthenticated, "missing bearer token")
+	}
+
+	claims, err := a.Verify(token)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token : %v", err)
+	}
+
+	id := &Identity{Subject: claims.Subject, Admin: claims.Admin, Tenant: claims.Tenant}
+
+	// keep the workload identity next to the user identity
+	if hasPeer {
+		id.Certificate = peerID.Certificate
+	}
+
+	return WithIdentity(ctx, id), nil
+}
+
+// Verify - parses and validates a signed token
+func (a *Authenticator) Verify(token string) (*Claims, error) {
+
+	// anyone can sign with an empty key
+	if a.cfg.SigningKey == "" {
+		return nil, fmt.Errorf("no signing key configured")
+	}
+
+	claims := new(Claims)
+
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+
+		// only accept the algorithm we sign with - never `none` or RSA/HMAC confusion
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+
+		return []byte(a.cfg.SigningKey), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+
+	if claims.ExpiresAt == 0 {
+		return nil, fmt.Errorf("token has no expiry")
+	}
+
+	if a.cfg.Issuer != "" && !claims.VerifyIssuer(a.cfg.Issuer, true) {
+		return nil, fmt.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+
+	return claims, nil
+}
+
+// Issue - signs a token for subject valid for ttl
+func (a *Authenticator) Issue(subject string, admin bool, ttl time.Duration) (string, error) {
+	return a.IssueFor(subject, "", admin, ttl)
+}
+
+// IssueFor - signs a token bound to tenant, empty for none
+func (a *Authenticator) IssueFor(subject, tenant string, admin bool, ttl time.Duration) (string, error) {
+
+	if a.cfg.SigningKey == "" {
+		return "", fmt.Errorf("no signing key configured")
+	}
+
+	now := time.Now()
+
+	claims := Claims{
+		Admin:  admin,
+		Tenant: tenant,
+		StandardClaims: jwt.StandardClaims{
+			Subject:   subject,
+			Issuer:    a.cfg.Issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(a.cfg.SigningKey))
+}
+
+// bearerToken - extracts the token from `authorization: Bearer <token>`
+func bearerToken(ctx context.Context) (string, error) {
+
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return "", nil
+	}
+
+	values := md.Get("authorization")
+
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	parts := strings.SplitN(values[0], " ", 2)
+
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return "", status.Errorf(codes.Unauthenticated, "authorization metadata must be a bearer token")
+	}
+
+	return strings.TrimSpace(parts[1]), nil
+}
+
+// matchMethod - reports whether method is in list. "/Service/*" matches all methods of a service
+func matchMethod(list []string, method string) bool {
+
+	for _, m := range list {
+
+		if m == method {
+			return true
+		}
+
+		if strings.HasSuffix(m, "/*") && strings.HasPrefix(method, strings.TrimSuffix(m, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contextStream - server stream with a replaced context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context -
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
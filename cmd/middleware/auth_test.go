@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func testAuth() *Authenticator {
+
+	return NewAuthenticator(config.Auth{
+		Enabled:    true,
+		SigningKey: "test-key",
+		Issuer:     "grpcourse",
+		Public:     []string{"/GreetService/*", "/BlogService/ReadBlog"},
+	})
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestAuthenticate(t *testing.T) {
+
+	a := testAuth()
+
+	token, err := a.Issue("1001", true, time.Minute)
+
+	if err != nil {
+		t.Fatalf("cannot issue token : %v", err)
+	}
+
+	ctx, err := a.authenticate(withToken(token), "/BlogService/DeleteBlog")
+
+	if err != nil {
+		t.Fatalf("valid token rejected : %v", err)
+	}
+
+	id, ok := IdentityFromContext(ctx)
+
+	if !ok || id.Subject != "1001" || !id.Admin {
+		t.Errorf("unexpected identity %+v", id)
+	}
+}
+
+func TestAuthenticateRejects(t *testing.T) {
+
+	a := testAuth()
+
+	expired, _ := a.Issue("1001", false, -time.Minute)
+
+	other, _ := NewAuthenticator(config.Auth{SigningKey: "other-key", Issuer: "grpcourse"}).Issue("1001", false, time.Minute)
+
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.StandardClaims{
+		Subject:   "1001",
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"no token", context.Background()},
+		{"expired", withToken(expired)},
+		{"wrong key", withToken(other)},
+		{"alg none", withToken(unsigned)},
+		{"not bearer", metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Basic abc"))},
+	}
+
+	for _, c := range cases {
+
+		_, err := a.authenticate(c.ctx, "/BlogService/UpdateBlog")
+
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("%s : expected Unauthenticated got %v", c.name, err)
+		}
+	}
+}
+
+func TestAuthenticatePublic(t *testing.T) {
+
+	a := testAuth()
+
+	for _, method := range []string{"/GreetService/Greet", "/BlogService/ReadBlog"} {
+
+		if _, err := a.authenticate(context.Background(), method); err != nil {
+			t.Errorf("%s should not need a token : %v", method, err)
+		}
+	}
+}
+
+func TestAuthenticateCertificateIsNoToken(t *testing.T) {
+
+	a := testAuth()
+
+	// a CA issued certificate named like a user id
+	ctx := WithIdentity(context.Background(), &Identity{Certificate: "1001"})
+
+	if _, err := a.authenticate(ctx, "/BlogService/UpdateBlog"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated without a token, got %v", err)
+	}
+
+	token, _ := a.Issue("1002", false, time.Minute)
+
+	ctx, err := a.authenticate(metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token)), "/BlogService/UpdateBlog")
+
+	if id, ok := IdentityFromContext(ctx); err != nil || !ok || id.Subject != "1002" || id.Certificate != "1001" {
+		t.Errorf("expected subject 1002 with certificate 1001, got %+v : %v", id, err)
+	}
+}
+
+func TestEmptySigningKey(t *testing.T) {
+
+	a := NewAuthenticator(config.Auth{Enabled: true, Issuer: "grpcourse"})
+
+	// what anyone could sign with the empty key
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Admin:          true,
+		StandardClaims: jwt.StandardClaims{Subject: "1001", Issuer: "grpcourse", ExpiresAt: time.Now().Add(time.Minute).Unix()},
+	}).SignedString([]byte(""))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Verify(forged); err == nil {
+		t.Errorf("token signed with an empty key accepted")
+	}
+
+	if _, err := a.Issue("1001", true, time.Minute); err == nil {
+		t.Errorf("token issued without a signing key")
+	}
+}
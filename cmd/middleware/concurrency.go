@@ -0,0 +1,303 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backoff - share of the limit kept after a slow or timed out call
+const backoff = 0.9
+
+// ConcurrencyLimiter - sheds calls over an adaptive per group limit (see
+// config.Concurrency). Put it early in the chain so shed calls cost little.
+type ConcurrencyLimiter struct {
+	cfg     config.Concurrency
+	groups  map[string]*limitGroup
+	methods map[string]*limitGroup
+
+	// OnLimit - called when a group limit changes
+	OnLimit func(group string, limit int)
+
+	// OnShed - called for every call turned away
+	OnShed func(group string)
+}
+
+// limitGroup - calls in progress and the current limit of one group
+type limitGroup struct {
+	name string
+	cfg  config.ConcurrencyGroup
+
+	mu        sync.Mutex
+	limit     float64
+	inflight  int
+	decreased time.Time
+}
+
+// NewConcurrencyLimiter - returns a ConcurrencyLimiter for the given settings
+func NewConcurrencyLimiter(cfg config.Concurrency) *ConcurrencyLimiter {
+
+	c := &ConcurrencyLimiter{cfg: cfg, groups: map[string]*limitGroup{}, methods: map[string]*limitGroup{}}
+
+	// by name so a method listed in two groups always lands in the same one
+	names := make([]string, 0, len(cfg.Groups))
+
+	for name := range cfg.Groups {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		g := &limitGroup{name: name, cfg: cfg.Groups[name], limit: float64(cfg.Groups[name].Initial)}
+
+		c.groups[name] = g
+
+		for _, m := range g.cfg.Methods {
+
+			if _, taken := c.methods[m]; !taken {
+				c.methods[m] = g
+			}
+		}
+	}
+
+	return c
+}
+
+// Unary - unary server interceptor
+func (c *ConcurrencyLimiter) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		g, err := c.acquire(info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if g == nil {
+			return handler(ctx, req)
+		}
+
+		started := time.Now()
+
+		res, err := handler(ctx, req)
+
+		c.release(g, started, time.Since(started), err)
+
+		return res, err
+	}
+}
+
+// Stream - stream server interceptor
+func (c *ConcurrencyLimiter) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		g, err := c.acquire(info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		if g == nil {
+			return handler(srv, ss)
+		}
+
+		ts := &timedStream{ServerStream: ss, started: time.Now()}
+
+		err = handler(srv, ts)
+
+		c.release(g, ts.started, ts.took(), err)
+
+		return err
+	}
+}
+
+// timedStream - measures how long the server takes to answer a stream: the
+// time to its first message, less the time spent waiting for the client's.
+// Downloads and uploads last as long as the client takes, which says
+// nothing about the load.
+type timedStream struct {
+	grpc.ServerStream
+	started time.Time
+
+	// one goroutine may send while another receives
+	mu     sync.Mutex
+	waited time.Duration
+	first  time.Time
+}
+
+// RecvMsg -
+func (s *timedStream) RecvMsg(m interface{}) error {
+
+	start := time.Now()
+
+	err := s.ServerStream.RecvMsg(m)
+
+	s.mu.Lock()
+
+	if s.first.IsZero() {
+		s.waited += time.Since(start)
+	}
+
+	s.mu.Unlock()
+
+	return err
+}
+
+// SendMsg -
+func (s *timedStream) SendMsg(m interface{}) error {
+
+	s.mu.Lock()
+
+	if s.first.IsZero() {
+		s.first = time.Now()
+	}
+
+	s.mu.Unlock()
+
+	return s.ServerStream.SendMsg(m)
+}
+
+// took - the server side time until the first message, or until the end
+// for streams that fail before sending any
+func (s *timedStream) took() time.Duration {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	end := s.first
+
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	return end.Sub(s.started) - s.waited
+}
+
+// Limits - current limit by group
+func (c *ConcurrencyLimiter) Limits() map[string]int {
+
+	limits := map[string]int{}
+
+	for name, g := range c.groups {
+
+		g.mu.Lock()
+		limits[name] = int(g.limit)
+		g.mu.Unlock()
+	}
+
+	return limits
+}
+
+// acquire - a slot in the group of method, nil for methods that are not limited
+func (c *ConcurrencyLimiter) acquire(method string) (*limitGroup, error) {
+
+	if !c.cfg.Enabled || matchMethod(c.cfg.Exempt, method) {
+		return nil, nil
+	}
+
+	g := c.group(method)
+
+	if g == nil {
+		return nil, nil
+	}
+
+	g.mu.Lock()
+
+	if g.inflight >= int(g.limit) {
+
+		g.mu.Unlock()
+
+		if c.OnShed != nil {
+			c.OnShed(g.name)
+		}
+
+		return nil, status.Errorf(codes.Unavailable, "server overloaded, %v calls are limited to %v at once", g.name, int(g.limit))
+	}
+
+	g.inflight++
+
+	g.mu.Unlock()
+
+	return g, nil
+}
+
+// release - frees the slot and adapts the limit to how the call went, took
+// is the latency the call signals
+func (c *ConcurrencyLimiter) release(g *limitGroup, started time.Time, took time.Duration, err error) {
+
+	now := time.Now()
+
+	g.mu.Lock()
+
+	before := int(g.limit)
+	busy := g.inflight >= before/2
+
+	g.inflight--
+
+	switch {
+	case overloaded(g.cfg, took, err):
+
+		// once per round - calls started before the last decrease saw the old load
+		if started.After(g.decreased) {
+			g.limit *= backoff
+			g.decreased = now
+		}
+
+	case busy:
+
+		// additive increase - about one per limit calls, only while the limit is in use
+		g.limit += 1 / g.limit
+	}
+
+	if g.limit < float64(g.cfg.Min) {
+		g.limit = float64(g.cfg.Min)
+	}
+
+	if g.limit > float64(g.cfg.Max) {
+		g.limit = float64(g.cfg.Max)
+	}
+
+	after := int(g.limit)
+
+	g.mu.Unlock()
+
+	if after != before && c.OnLimit != nil {
+		c.OnLimit(g.name, after)
+	}
+}
+
+// group - the group listing method, exact names before whole services
+func (c *ConcurrencyLimiter) group(method string) *limitGroup {
+
+	if g, ok := c.methods[method]; ok {
+		return g
+	}
+
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		return c.methods[method[:i]+"/*"]
+	}
+
+	return nil
+}
+
+// overloaded - whether a finished call signals the group is over capacity
+func overloaded(cfg config.ConcurrencyGroup, took time.Duration, err error) bool {
+
+	switch status.Code(err) {
+	case codes.DeadlineExceeded, codes.Unavailable:
+		return true
+	}
+
+	return cfg.Target > 0 && took > time.Duration(cfg.Target)
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+
+	c := NewConcurrencyLimiter(config.Concurrency{
+		Enabled: true,
+		Groups: map[string]config.ConcurrencyGroup{
+			"reads": {Methods: []string{"/BlogService/ReadBlog"}, Initial: 2, Min: 1, Max: 4},
+		},
+		Exempt: []string{"/grpc.health.v1.Health/*"},
+	})
+
+	call := func(method string, handler grpc.UnaryHandler) error {
+		_, err := c.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	// nest calls to hold slots: the third read is shed, health always passes
+	err := call("/BlogService/ReadBlog", func(ctx context.Context, req interface{}) (interface{}, error) {
+
+		return nil, call("/BlogService/ReadBlog", func(ctx context.Context, req interface{}) (interface{}, error) {
+
+			if err := call("/BlogService/ReadBlog", ok); status.Code(err) != codes.Unavailable {
+				t.Errorf("expected the call to be shed, got %v", err)
+			}
+
+			return nil, call("/grpc.health.v1.Health/Check", ok)
+		})
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// calls using the limit raise it
+	for i := 0; i < 3; i++ {
+		call("/BlogService/ReadBlog", ok)
+	}
+
+	reads := c.groups["reads"]
+
+	if reads.limit <= 2 {
+		t.Errorf("expected the limit to grow, got %v", reads.limit)
+	}
+
+	// failures from an overloaded dependency lower it
+	before := reads.limit
+
+	call("/BlogService/ReadBlog", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Errorf(codes.Unavailable, "database unavailable")
+	})
+
+	if reads.limit >= before {
+		t.Errorf("expected the limit to shrink from %v, got %v", before, reads.limit)
+	}
+}
+
+// slowClient - a stream whose client takes delay to send each message
+type slowClient struct {
+	grpc.ServerStream
+	delay time.Duration
+}
+
+func (s *slowClient) Context() context.Context { return context.Background() }
+
+func (s *slowClient) RecvMsg(m interface{}) error {
+	time.Sleep(s.delay)
+	return nil
+}
+
+func (s *slowClient) SendMsg(m interface{}) error { return nil }
+
+func TestConcurrencyLimiterStreams(t *testing.T) {
+
+	c := NewConcurrencyLimiter(config.Concurrency{
+		Enabled: true,
+		Groups: map[string]config.ConcurrencyGroup{
+			"uploads": {Methods: []string{"/BlogService/CreateBlog"}, Initial: 4, Min: 1, Max: 4, Target: config.Duration(20 * time.Millisecond)},
+		},
+	})
+
+	uploads := c.groups["uploads"]
+
+	call := func(handler grpc.StreamHandler) {
+		c.Stream()(nil, &slowClient{delay: 15 * time.Millisecond}, &grpc.StreamServerInfo{FullMethod: "/BlogService/CreateBlog"}, handler)
+	}
+
+	// a slow client, or a long download after a quick first message, is no overload
+	call(func(srv interface{}, ss grpc.ServerStream) error {
+
+		for i := 0; i < 4; i++ {
+			ss.RecvMsg(nil)
+		}
+
+		ss.SendMsg(nil)
+
+		time.Sleep(40 * time.Millisecond)
+
+		return ss.SendMsg(nil)
+	})
+
+	if uploads.limit != 4 {
+		t.Errorf("expected the limit to stay at 4, got %v", uploads.limit)
+	}
+
+	// a server slow to answer is
+	call(func(srv interface{}, ss grpc.ServerStream) error {
+
+		ss.RecvMsg(nil)
+
+		time.Sleep(40 * time.Millisecond)
+
+		return ss.SendMsg(nil)
+	})
+
+	if uploads.limit >= 4 {
+		t.Errorf("expected the limit to shrink, got %v", uploads.limit)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// Call - an RPC currently being served
+type Call struct {
+	Method  string
+	Peer    string
+	Started time.Time
+}
+
+// Tracker - keeps the calls in progress so shutdown can wait for them.
+// Put it first in the chain so rejected calls are counted too.
+type Tracker struct {
+	mu    sync.Mutex
+	next  uint64
+	calls map[uint64]Call
+}
+
+// NewTracker - returns an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{calls: map[uint64]Call{}}
+}
+
+// Unary - unary server interceptor
+func (t *Tracker) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		defer t.finish(t.start(ctx, info.FullMethod))
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - stream server interceptor
+func (t *Tracker) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		defer t.finish(t.start(ss.Context(), info.FullMethod))
+
+		return handler(srv, ss)
+	}
+}
+
+// Len - number of calls in progress
+func (t *Tracker) Len() int {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.calls)
+}
+
+// Active - the calls in progress, oldest first
+func (t *Tracker) Active() []Call {
+
+	t.mu.Lock()
+
+	calls := make([]Call, 0, len(t.calls))
+
+	for _, c := range t.calls {
+		calls = append(calls, c)
+	}
+
+	t.mu.Unlock()
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].Started.Before(calls[j].Started)
+	})
+
+	return calls
+}
+
+// Wait - returns once no call is in progress, or with the ctx error
+func (t *Tracker) Wait(ctx context.Context) error {
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for t.Len() > 0 {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (t *Tracker) start(ctx context.Context, method string) uint64 {
+
+	c := Call{Method: method, Started: time.Now()}
+
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		c.Peer = pr.Addr.String()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.next++
+	t.calls[t.next] = c
+
+	return t.next
+}
+
+func (t *Tracker) finish(id uint64) {
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.calls, id)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestTrackerWait(t *testing.T) {
+
+	tr := NewTracker()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go tr.Unary()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/BlogService/CreateBlog"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+
+		close(started)
+		<-release
+
+		return nil, nil
+	})
+
+	<-started
+
+	if calls := tr.Active(); len(calls) != 1 || calls[0].Method != "/BlogService/CreateBlog" {
+		t.Fatalf("unexpected active calls %+v", calls)
+	}
+
+	// the call is still running - waiting must give up with the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := tr.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline, got %v", err)
+	}
+
+	close(release)
+
+	if err := tr.Wait(context.Background()); err != nil || tr.Len() != 0 {
+		t.Fatalf("calls left after the drain : %v %v", tr.Len(), err)
+	}
+}
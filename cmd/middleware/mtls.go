@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"grpcourse/config"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// CertificateNames - identities carried by a client certificate: the subject
+// common name followed by the URI, DNS and email SANs
+func CertificateNames(cert *x509.Certificate) []string {
+
+	var names []string
+
+	if cert.Subject.CommonName != "" {
+		names = append(names, cert.Subject.CommonName)
+	}
+
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+
+	return names
+}
+
+// PeerAuthorizer - maps verified client certificates to caller identities
+// and enforces the per method allow/deny lists
+type PeerAuthorizer struct {
+	cfg config.TLS
+}
+
+// NewPeerAuthorizer - returns a PeerAuthorizer for the given TLS settings
+func NewPeerAuthorizer(cfg config.TLS) *PeerAuthorizer {
+	return &PeerAuthorizer{cfg: cfg}
+}
+
+// Unary - unary server interceptor
+func (p *PeerAuthorizer) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := p.authorize(ctx, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - stream server interceptor
+func (p *PeerAuthorizer) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := p.authorize(ss.Context(), info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize - attaches the certificate identity to ctx and checks it against the access lists
+func (p *PeerAuthorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+
+	names := peerNames(ctx)
+
+	if len(names) == 0 {
+
+		// the handshake already enforces client certificates under mTLS,
+		// this only trips for connections that bypass it. Local sockets are
+		// guarded by their file permissions instead.
+		if p.cfg.ClientCA != "" && !isLocal(ctx) {
+			return nil, status.Errorf(codes.Unauthenticated, "client certificate required")
+		}
+
+		return ctx, nil
+	}
+
+	if access, ok := p.access(method); ok {
+
+		if matchAny(access.Deny, names) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v may not call %v", names[0], method)
+		}
+
+		if len(access.Allow) > 0 && !matchAny(access.Allow, names) {
+			return nil, status.Errorf(codes.PermissionDenied, "%v may not call %v", names[0], method)
+		}
+	}
+
+	// a certificate names a workload, never a user - the subject (and so
+	// blog ownership) only ever comes from a token
+	return WithIdentity(ctx, &Identity{Certificate: names[0]}), nil
+}
+
+// access - the most specific access list for method: exact, service wide, then "*"
+func (p *PeerAuthorizer) access(method string) (config.Access, bool) {
+
+	if a, ok := p.cfg.Access[method]; ok {
+		return a, true
+	}
+
+	if i := strings.LastIndex(method, "/"); i > 0 {
+
+		if a, ok := p.cfg.Access[method[:i]+"/*"]; ok {
+			return a, true
+		}
+	}
+
+	a, ok := p.cfg.Access["*"]
+
+	return a, ok
+}
+
+type localKey struct{}
+
+// WithLocal - marks ctx as belonging to a connection on a unix socket. The
+// gRPC transport reports those through the peer address, HTTP servers use this.
+func WithLocal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localKey{}, true)
+}
+
+// isLocal - reports whether the caller is connected through a unix socket
+func isLocal(ctx context.Context) bool {
+
+	if local, _ := ctx.Value(localKey{}).(bool); local {
+		return true
+	}
+
+	pr, ok := peer.FromContext(ctx)
+
+	return ok && pr.Addr != nil && pr.Addr.Network() == "unix"
+}
+
+// peerNames - identities of the verified client certificate on the connection
+func peerNames(ctx context.Context) []string {
+
+	pr, ok := peer.FromContext(ctx)
+
+	if !ok {
+		return nil
+	}
+
+	info, ok := pr.AuthInfo.(credentials.TLSInfo)
+
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return CertificateNames(info.State.VerifiedChains[0][0])
+}
+
+// matchAny - reports whether any of names is in list, "*" matches everyone
+func matchAny(list, names []string) bool {
+
+	for _, entry := range list {
+
+		if entry == "*" {
+			return true
+		}
+
+		for _, n := range names {
+			if entry == n {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"grpcourse/config"
+	"net"
+	"net/url"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerWithCert(cn string, uris ...string) context.Context {
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+
+	for _, u := range uris {
+		parsed, _ := url.Parse(u)
+		cert.URIs = append(cert.URIs, parsed)
+	}
+
+	info := credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}
+
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: info})
+}
+
+func TestPeerAuthorizer(t *testing.T) {
+
+	p := NewPeerAuthorizer(config.TLS{
+		ClientCA: "ca.crt",
+		Access: map[string]config.Access{
+			"/BlogService/DeleteBlog": {Allow: []string{"spiffe://grpcourse/admin"}},
+			"/BlogService/*":          {Deny: []string{"banned"}},
+		},
+	})
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+	}{
+		{"no certificate", context.Background(), "/GreetService/Greet", codes.Unauthenticated},
+		{"allowed by uri san", peerWithCert("ops", "spiffe://grpcourse/admin"), "/BlogService/DeleteBlog", codes.OK},
+		{"not in allow list", peerWithCert("blog-client"), "/BlogService/DeleteBlog", codes.PermissionDenied},
+		{"denied service wide", peerWithCert("banned"), "/BlogService/ReadBlog", codes.PermissionDenied},
+		{"no rules", peerWithCert("banned"), "/GreetService/Greet", codes.OK},
+	}
+
+	for _, c := range cases {
+
+		ctx, err := p.authorize(c.ctx, c.method)
+
+		if status.Code(err) != c.code {
+			t.Errorf("%s : expected %v got %v", c.name, c.code, err)
+			continue
+		}
+
+		if err == nil {
+
+			if id, ok := IdentityFromContext(ctx); !ok || id.Certificate == "" || id.Subject != "" {
+				t.Errorf("%s : expected a certificate identity without subject in context", c.name)
+			}
+		}
+	}
+}
+
+func TestPeerAuthorizerLocalSocket(t *testing.T) {
+
+	p := NewPeerAuthorizer(config.TLS{ClientCA: "ca.crt"})
+
+	unix := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.UnixAddr{Name: "/tmp/grpcourse.sock", Net: "unix"}})
+
+	for name, ctx := range map[string]context.Context{"grpc transport": unix, "http server": WithLocal(context.Background())} {
+
+		if _, err := p.authorize(ctx, "/GreetService/Greet"); err != nil {
+			t.Errorf("%s : local callers need no certificate, got %v", name, err)
+		}
+	}
+}
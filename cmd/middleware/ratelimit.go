@@ -0,0 +1,362 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/config"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// concurrentRetry - suggested wait when a caller has too many calls open,
+// there is no way to tell when one of them finishes
+const concurrentRetry = time.Second
+
+// idleBucket - buckets unused for this long are dropped
+const idleBucket = 10 * time.Minute
+
+// RateLimiter - enforces config.RateLimit with a token bucket per caller and
+// limit entry. Put it after the auth interceptors so callers are identified.
+type RateLimiter struct {
+	cfg config.RateLimit
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+	swept   time.Time
+}
+
+type bucketKey struct {
+	entry  string
+	caller string
+}
+
+// bucket - the budget of one caller on one limit entry
+type bucket struct {
+	calls  *rate.Limiter
+	bytes  *rate.Limiter
+	active int
+	seen   time.Time
+}
+
+// NewRateLimiter - returns a RateLimiter for the given settings
+func NewRateLimiter(cfg config.RateLimit) *RateLimiter {
+	return &RateLimiter{cfg: cfg, buckets: map[bucketKey]*bucket{}, swept: time.Now()}
+}
+
+// Unary - unary server interceptor
+func (l *RateLimiter) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		b, err := l.admit(ctx, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if b == nil {
+			return handler(ctx, req)
+		}
+
+		defer l.release(b)
+
+		// the request is already read, holding it back keeps the caller's average in check
+		if msg, ok := req.(proto.Message); ok {
+
+			if err := throttle(ctx, b.bytes, proto.Size(msg)); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - stream server interceptor
+func (l *RateLimiter) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		b, err := l.admit(ss.Context(), info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		if b == nil {
+			return handler(srv, ss)
+		}
+
+		defer l.release(b)
+
+		return handler(srv, &throttledStream{ServerStream: ss, bytes: b.bytes})
+	}
+}
+
+// admit - takes a call from the caller's bucket, nil when the method has no limit
+func (l *RateLimiter) admit(ctx context.Context, method string) (*bucket, error) {
+
+	if !l.cfg.Enabled {
+		return nil, nil
+	}
+
+	entry, limit, ok := l.limit(method)
+
+	if !ok {
+		return nil, nil
+	}
+
+	caller := l.callerKey(ctx)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.sweep(now)
+
+	key := bucketKey{entry: entry, caller: caller}
+
+	b, ok := l.buckets[key]
+
+	if !ok {
+		b = newBucket(limit)
+		l.buckets[key] = b
+	}
+
+	b.seen = now
+
+	if limit.Concurrent > 0 && b.active >= limit.Concurrent {
+		return nil, exhausted(concurrentRetry, fmt.Sprintf("%v has %v calls to %v open, the limit is %v", caller, b.active, method, limit.Concurrent))
+	}
+
+	if b.calls != nil {
+
+		r := b.calls.ReserveN(now, 1)
+
+		if delay := r.DelayFrom(now); delay > 0 {
+			r.CancelAt(now)
+			return nil, exhausted(delay, fmt.Sprintf("too many calls to %v from %v, retry in %v", method, caller, delay.Round(time.Millisecond)))
+		}
+	}
+
+	b.active++
+
+	return b, nil
+}
+
+// release - the call admitted with b finished
+func (l *RateLimiter) release(b *bucket) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b.active--
+}
+
+// limit - the most specific entry for method: exact, service wide, then "*"
+func (l *RateLimiter) limit(method string) (string, config.Limit, bool) {
+
+	if lim, ok := l.cfg.Methods[method]; ok {
+		return method, lim, true
+	}
+
+	if i := strings.LastIndex(method, "/"); i > 0 {
+
+		if lim, ok := l.cfg.Methods[method[:i]+"/*"]; ok {
+			return method[:i] + "/*", lim, true
+		}
+	}
+
+	lim, ok := l.cfg.Methods["*"]
+
+	return "*", lim, ok
+}
+
+// sweep - drops idle buckets, at most once a minute. Called with mu held.
+func (l *RateLimiter) sweep(now time.Time) {
+
+	if now.Sub(l.swept) < time.Minute {
+		return
+	}
+
+	l.swept = now
+
+	for key, b := range l.buckets {
+
+		if b.active == 0 && now.Sub(b.seen) > idleBucket {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func newBucket(limit config.Limit) *bucket {
+
+	b := new(bucket)
+
+	if limit.Rate > 0 {
+
+		burst := limit.Burst
+
+		if burst < 1 {
+			burst = 1
+		}
+
+		b.calls = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+	}
+
+	if limit.BytesPerSecond > 0 {
+		b.bytes = rate.NewLimiter(rate.Limit(limit.BytesPerSecond), limit.BytesPerSecond)
+	}
+
+	return b
+}
+
+// callerKey - token subject, client certificate identity or IP address of
+// the caller. Calls relayed by a configured gateway count for the client it
+// names in x-forwarded-for.
+func (l *RateLimiter) callerKey(ctx context.Context) string {
+
+	id, _ := IdentityFromContext(ctx)
+
+	if id != nil && id.Subject != "" {
+		return "sub:" + id.Subject
+	}
+
+	var host string
+
+	pr, ok := peer.FromContext(ctx)
+
+	if ok && pr.Addr != nil {
+		host, _, _ = net.SplitHostPort(pr.Addr.String())
+	}
+
+	if (host != "" && l.gateway(host)) || (id != nil && id.Certificate != "" && l.gateway(id.Certificate)) {
+
+		if fwd := forwardedFor(ctx); fwd != "" {
+			return "ip:" + fwd
+		}
+	}
+
+	if id != nil && id.Certificate != "" {
+		return "cert:" + id.Certificate
+	}
+
+	if host != "" {
+		return "ip:" + host
+	}
+
+	if !ok || pr.Addr == nil {
+		return "unknown"
+	}
+
+	// unix sockets have no port
+	return pr.Addr.Network() + ":" + pr.Addr.String()
+}
+
+// gateway - reports whether peer, an IP address or certificate identity, is
+// in cfg.Gateways
+func (l *RateLimiter) gateway(peer string) bool {
+
+	for _, g := range l.cfg.Gateways {
+
+		if g == peer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedFor - the original client of a proxied call. Clients may send
+// x-forwarded-for themselves, only the last entry - appended by the gateway -
+// is to be believed.
+func forwardedFor(ctx context.Context) string {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("x-forwarded-for")
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	entries := strings.Split(values[len(values)-1], ",")
+
+	return strings.TrimSpace(entries[len(entries)-1])
+}
+
+// throttle - waits until n bytes fit in the caller's bandwidth
+func throttle(ctx context.Context, lim *rate.Limiter, n int) error {
+
+	if lim == nil {
+		return nil
+	}
+
+	// WaitN refuses more than the burst at once
+	for n > 0 {
+
+		take := n
+
+		if take > lim.Burst() {
+			take = lim.Burst()
+		}
+
+		if err := lim.WaitN(ctx, take); err != nil {
+
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+
+			return status.Errorf(codes.ResourceExhausted, fmt.Sprintf("upload throttled : %v", err))
+		}
+
+		n -= take
+	}
+
+	return nil
+}
+
+// exhausted - ResourceExhausted carrying RetryInfo so clients know when to come back
+func exhausted(delay time.Duration, msg string) error {
+
+	st, err := status.New(codes.ResourceExhausted, msg).WithDetails(&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(delay)})
+
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, msg)
+	}
+
+	return st.Err()
+}
+
+// throttledStream - reads no faster than the caller's bandwidth. Not reading
+// leaves the HTTP/2 flow control window closed, so the client slows down too.
+type throttledStream struct {
+	grpc.ServerStream
+	bytes *rate.Limiter
+}
+
+func (s *throttledStream) RecvMsg(m interface{}) error {
+
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(proto.Message); ok {
+		return throttle(s.Context(), s.bytes, proto.Size(msg))
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiter(t *testing.T) {
+
+	l := NewRateLimiter(config.RateLimit{
+		Enabled: true,
+		Methods: map[string]config.Limit{
+			"/BlogService/ListBlog": {Rate: 1, Burst: 2},
+			"/BlogService/*":        {Concurrent: 1},
+		},
+		Gateways: []string{"127.0.0.1"},
+	})
+
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	call := func(ctx context.Context, method string, handler grpc.UnaryHandler) error {
+		_, err := l.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	alice := WithIdentity(context.Background(), &Identity{Subject: "alice"})
+	bob := WithIdentity(context.Background(), &Identity{Subject: "bob"})
+
+	// the burst, then a rejection telling when to retry
+	for i := 0; i < 2; i++ {
+		if err := call(alice, "/BlogService/ListBlog", ok); err != nil {
+			t.Fatalf("call %v rejected : %v", i, err)
+		}
+	}
+
+	err := call(alice, "/BlogService/ListBlog", ok)
+
+	st := status.Convert(err)
+
+	if st.Code() != codes.ResourceExhausted || len(st.Details()) != 1 {
+		t.Fatalf("expected ResourceExhausted with details, got %v", err)
+	}
+
+	if info, isRetry := st.Details()[0].(*errdetails.RetryInfo); !isRetry || info.GetRetryDelay().GetNanos() == 0 && info.GetRetryDelay().GetSeconds() == 0 {
+		t.Errorf("expected a retry delay, got %v", st.Details()[0])
+	}
+
+	// every caller has a budget of its own
+	if err := call(bob, "/BlogService/ListBlog", ok); err != nil {
+		t.Errorf("bob was limited by alice's calls : %v", err)
+	}
+
+	// one call at a time on the rest of the service
+	err = call(alice, "/BlogService/DeleteBlog", func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, call(alice, "/BlogService/ReadBlog", ok)
+	})
+
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the nested call to be rejected, got %v", err)
+	}
+
+	if err := call(alice, "/BlogService/ReadBlog", ok); err != nil {
+		t.Errorf("the slot was not released : %v", err)
+	}
+
+	// methods without an entry are not limited
+	if err := call(alice, "/GreetService/Greet", ok); err != nil {
+		t.Errorf("unexpected limit : %v", err)
+	}
+
+	// calls relayed by the gateway are told apart by the original client
+	gw := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4000}})
+
+	if key := l.callerKey(metadata.NewIncomingContext(gw, metadata.Pairs("x-forwarded-for", "203.0.113.7, 10.0.0.1"))); key != "ip:10.0.0.1" {
+		t.Errorf("expected the entry appended by the gateway, got %v", key)
+	}
+}
+
+func TestCallerKey(t *testing.T) {
+
+	l := NewRateLimiter(config.RateLimit{Gateways: []string{"127.0.0.1", "gateway"}})
+
+	from := func(ip net.IP, cert string, fwd ...string) context.Context {
+
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: ip, Port: 4000}})
+
+		if cert != "" {
+			ctx = WithIdentity(ctx, &Identity{Certificate: cert})
+		}
+
+		return metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", strings.Join(fwd, ", ")))
+	}
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"gateway", from(net.IPv4(127, 0, 0, 1), "", "203.0.113.7"), "ip:203.0.113.7"},
+		{"spoofed through the gateway", from(net.IPv4(127, 0, 0, 1), "", "1.2.3.4", "203.0.113.7"), "ip:203.0.113.7"},
+		{"gateway certificate", from(net.IPv4(10, 0, 0, 9), "gateway", "203.0.113.7"), "ip:203.0.113.7"},
+		{"other loopback", from(net.IPv4(127, 0, 0, 2), "", "203.0.113.7"), "ip:127.0.0.2"},
+		{"client", from(net.IPv4(198, 51, 100, 1), "", "203.0.113.7"), "ip:198.51.100.1"},
+		{"other certificate", from(net.IPv4(10, 0, 0, 9), "worker", "203.0.113.7"), "cert:worker"},
+	}
+
+	for _, c := range cases {
+
+		if got := l.callerKey(c.ctx); got != c.want {
+			t.Errorf("%v : expected %v, got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader - metadata key carrying the request id both ways
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLen - longer ids from callers are replaced
+const maxRequestIDLen = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext - the id attached by the RequestID interceptors
+func RequestIDFromContext(ctx context.Context) string {
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// UnaryRequestID - keeps the caller's x-request-id or makes one up, and
+// returns it in the response headers
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withRequestID(ctx), req)
+	}
+}
+
+// StreamRequestID - stream counterpart of UnaryRequestID
+func StreamRequestID() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+	}
+}
+
+func withRequestID(ctx context.Context) context.Context {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var id string
+
+	if values := md.Get(RequestIDHeader); len(values) > 0 && values[0] != "" && len(values[0]) <= maxRequestIDLen {
+		id = values[0]
+	} else {
+		id = newRequestID()
+	}
+
+	// best effort - fails only once headers were sent
+	grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func newRequestID() string {
+
+	b := make([]byte, 16)
+
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestID(t *testing.T) {
+
+	// 1. the caller's id is kept
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "abc-123"))
+
+	if id := RequestIDFromContext(withRequestID(ctx)); id != "abc-123" {
+		t.Errorf("expected abc-123, got %q", id)
+	}
+
+	// 2. missing or oversized ids are replaced
+	for _, md := range []metadata.MD{nil, metadata.Pairs(RequestIDHeader, strings.Repeat("x", maxRequestIDLen+1))} {
+
+		id := RequestIDFromContext(withRequestID(metadata.NewIncomingContext(context.Background(), md)))
+
+		if len(id) != 32 {
+			t.Errorf("expected a generated id, got %q", id)
+		}
+	}
+}
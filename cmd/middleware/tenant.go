@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"grpcourse/data/tenant"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type tenantKey struct{}
+
+// WithTenant - returns a copy of ctx acting in tenant id
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// TenantFromContext - the tenant attached by the TenantResolver, empty without one
+func TenantFromContext(ctx context.Context) string {
+
+	id, _ := ctx.Value(tenantKey{}).(string)
+
+	return id
+}
+
+// TenantResolver - picks the tenant of every call that is not exempt. Put it
+// after the auth interceptors, the token claim decides when there is one.
+type TenantResolver struct {
+	cfg   config.Tenancy
+	known func(ctx context.Context, id string) (bool, error)
+}
+
+// NewTenantResolver - known reports whether a tenant was provisioned (tenant.Registry.Exists)
+func NewTenantResolver(cfg config.Tenancy, known func(ctx context.Context, id string) (bool, error)) *TenantResolver {
+	return &TenantResolver{cfg: cfg, known: known}
+}
+
+// Unary - unary server interceptor
+func (t *TenantResolver) Unary() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		ctx, err := t.resolve(ctx, info.FullMethod)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream - stream server interceptor
+func (t *TenantResolver) Stream() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		ctx, err := t.resolve(ss.Context(), info.FullMethod)
+
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// resolve - the token claim, then the header, then the default tenant.
+// Only admin tokens may pick a tenant with the header. Everyone else -
+// other tokens, certificate identities and anonymous callers - gets the
+// default tenant, or the one their token is bound to.
+func (t *TenantResolver) resolve(ctx context.Context, method string) (context.Context, error) {
+
+	if !t.cfg.Enabled || matchMethod(t.cfg.Exempt, method) {
+		return ctx, nil
+	}
+
+	var requested string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+
+		if values := md.Get(t.cfg.Header); len(values) > 0 {
+			requested = values[0]
+		}
+	}
+
+	id, _ := IdentityFromContext(ctx)
+
+	var name string
+
+	switch {
+	case id != nil && id.Tenant != "":
+
+		if requested != "" && requested != id.Tenant {
+			return nil, status.Errorf(codes.PermissionDenied, "token of %v is bound to another tenant", id.Subject)
+		}
+
+		name = id.Tenant
+
+	// the subject only comes from a verified token
+	case id != nil && id.Subject != "" && id.Admin && requested != "":
+		name = requested
+
+	case requested != "" && requested != t.cfg.Default:
+		return nil, status.Errorf(codes.PermissionDenied, "only admin tokens may pick a tenant with %v", t.cfg.Header)
+
+	default:
+		name = t.cfg.Default
+	}
+
+	if name == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "no tenant, send the %v metadata", t.cfg.Header)
+	}
+
+	if !tenant.Valid(name) {
+		return nil, status.Errorf(codes.InvalidArgument, "%q is not a valid tenant id", name)
+	}
+
+	ok, err := t.known(ctx, name)
+
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "cannot look up tenant : %v", err)
+	}
+
+	// tenants are provisioned up front, a typo must not create a database
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "unknown tenant %q", name)
+	}
+
+	return WithTenant(ctx, name), nil
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"grpcourse/config"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenantResolver(t *testing.T) {
+
+	a := testAuth()
+
+	r := NewTenantResolver(config.Tenancy{Enabled: true, Header: "x-tenant-id", Exempt: []string{"/GreetService/*"}},
+		func(_ context.Context, id string) (bool, error) { return id == "red" || id == "blue", nil })
+
+	bound, _ := a.IssueFor("1001", "red", false, time.Minute)
+	unbound, _ := a.Issue("1001", false, time.Minute)
+	admin, _ := a.Issue("ops", true, time.Minute)
+
+	// call - authenticates token (if any) and resolves the tenant of a blog call
+	call := func(token, header string) (string, codes.Code) {
+
+		md := metadata.MD{}
+
+		if token != "" {
+			md.Set("authorization", "Bearer "+token)
+		}
+
+		if header != "" {
+			md.Set("x-tenant-id", header)
+		}
+
+		ctx, err := a.authenticate(metadata.NewIncomingContext(context.Background(), md), "/BlogService/ReadBlog")
+
+		if err != nil {
+			t.Fatalf("cannot authenticate : %v", err)
+		}
+
+		ctx, err = r.resolve(ctx, "/BlogService/ReadBlog")
+
+		if err != nil {
+			return "", status.Code(err)
+		}
+
+		return TenantFromContext(ctx), codes.OK
+	}
+
+	tests := []struct {
+		name, token, header, tenant string
+		code                        codes.Code
+	}{
+		{"claim", bound, "", "red", codes.OK},
+		{"claim and same header", bound, "red", "red", codes.OK},
+		{"header contradicts claim", bound, "blue", "", codes.PermissionDenied},
+		{"unbound token picks", unbound, "blue", "", codes.PermissionDenied},
+		{"admin picks", admin, "blue", "blue", codes.OK},
+		{"anonymous picks", "", "blue", "", codes.PermissionDenied},
+		{"unknown tenant", admin, "green", "", codes.PermissionDenied},
+		{"invalid tenant", admin, "../red", "", codes.InvalidArgument},
+		{"no tenant", "", "", "", codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+
+		tenant, code := call(tt.token, tt.header)
+
+		if code != tt.code || tenant != tt.tenant {
+			t.Errorf("%v : expected %q %v, got %q %v", tt.name, tt.tenant, tt.code, tenant, code)
+		}
+	}
+
+	// exempt methods need no tenant
+	if _, err := r.resolve(context.Background(), "/GreetService/Greet"); err != nil {
+		t.Errorf("exempt method rejected : %v", err)
+	}
+
+	// a certificate identity is no admin token
+	cert := metadata.NewIncomingContext(WithIdentity(context.Background(), &Identity{Certificate: "ops", Admin: true}), metadata.Pairs("x-tenant-id", "blue"))
+
+	if _, err := r.resolve(cert, "/BlogService/ReadBlog"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("certificate picks : expected PermissionDenied, got %v", err)
+	}
+}
+
+func TestTenantResolverDefault(t *testing.T) {
+
+	r := NewTenantResolver(config.Tenancy{Enabled: true, Header: "x-tenant-id", Default: "red"},
+		func(_ context.Context, id string) (bool, error) { return id == "red" || id == "blue", nil })
+
+	for header, code := range map[string]codes.Code{"": codes.OK, "red": codes.OK, "blue": codes.PermissionDenied} {
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant-id", header))
+
+		ctx, err := r.resolve(ctx, "/BlogService/ListBlog")
+
+		if status.Code(err) != code {
+			t.Errorf("%q : expected %v, got %v", header, code, err)
+			continue
+		}
+
+		if err == nil && TenantFromContext(ctx) != "red" {
+			t.Errorf("%q : expected the default tenant, got %q", header, TenantFromContext(ctx))
+		}
+	}
+}
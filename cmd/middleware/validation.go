@@ -0,0 +1,313 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/proto"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Rule - checks a single field. It returns a description of the violation,
+// or an empty string when the value is acceptable.
+type Rule func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string
+
+// Rules - maps a dotted field path (proto field names) to the rules for that field.
+// Rules on nested paths only run when every parent message on the path is set,
+// so `blog.title` is skipped on a CreateBlogRequest that carries an image chunk.
+type Rules map[string][]Rule
+
+var (
+	mu       sync.RWMutex
+	registry = map[protoreflect.FullName]Rules{}
+)
+
+// Register - declares the validation rules for a message type. It panics
+// on a path that does not resolve, so a typo fails at boot rather than on
+// the first request.
+func Register(msg proto.Message, rules Rules) {
+
+	desc := proto.MessageReflect(msg).Descriptor()
+
+	for path := range rules {
+
+		if err := checkPath(desc, path); err != nil {
+			panic(fmt.Sprintf("validation: %v", err))
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	registry[desc.FullName()] = rules
+}
+
+// checkPath - reports a path naming a field desc does not have, or going
+// through a field that is not a single message
+func checkPath(desc protoreflect.MessageDescriptor, path string) error {
+
+	parts := strings.Split(path, ".")
+
+	for i, name := range parts {
+
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+
+		if fd == nil {
+			return fmt.Errorf("%s has no field %q", desc.FullName(), name)
+		}
+
+		if i == len(parts)-1 {
+			return nil
+		}
+
+		if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+			return fmt.Errorf("%s.%s is not a message, %q cannot go through it", desc.FullName(), name, path)
+		}
+
+		desc = fd.Message()
+	}
+
+	return nil
+}
+
+// Validate - checks msg against its registered rules
+func Validate(msg proto.Message) []*errdetails.BadRequest_FieldViolation {
+
+	m := proto.MessageReflect(msg)
+
+	mu.RLock()
+	rules, ok := registry[m.Descriptor().FullName()]
+	mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	// report violations in a stable order
+	paths := make([]string, 0, len(rules))
+
+	for path := range rules {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	var violations []*errdetails.BadRequest_FieldViolation
+
+	for _, path := range paths {
+
+		fd, v, set, ok := lookup(m, path)
+
+		if !ok {
+			continue
+		}
+
+		for _, rule := range rules[path] {
+
+			if desc := rule(fd, v, set); desc != "" {
+
+				violations = append(violations, &errdetails.BadRequest_FieldViolation{
+					Field:       path,
+					Description: desc,
+				})
+
+				// first failing rule is enough for a field
+				break
+			}
+		}
+	}
+
+	return violations
+}
+
+// lookup - resolves a dotted path, checked by Register, to a field
+// descriptor and value. ok is false when a parent message on the path is
+// not set.
+func lookup(m protoreflect.Message, path string) (protoreflect.FieldDescriptor, protoreflect.Value, bool, bool) {
+
+	parts := strings.Split(path, ".")
+
+	for i, name := range parts {
+
+		fd := m.Descriptor().Fields().ByName(protoreflect.Name(name))
+
+		if fd == nil {
+			return nil, protoreflect.Value{}, false, false
+		}
+
+		if i == len(parts)-1 {
+			return fd, m.Get(fd), m.Has(fd), true
+		}
+
+		if fd.Kind() != protoreflect.MessageKind || !m.Has(fd) {
+			return nil, protoreflect.Value{}, false, false
+		}
+
+		m = m.Get(fd).Message()
+	}
+
+	return nil, protoreflect.Value{}, false, false
+}
+
+// Required - field must be set (non-empty for strings and bytes, non-zero for numbers)
+func Required() Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		if !set {
+			return "is required"
+		}
+
+		return ""
+	}
+}
+
+// Length - string length in characters must be within [min, max]
+func Length(min, max int) Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		n := utf8.RuneCountInString(v.String())
+
+		if n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d characters long", min, max)
+		}
+
+		return ""
+	}
+}
+
+// MaxBytes - bytes field must not be longer than max
+func MaxBytes(max int) Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		if len(v.Bytes()) > max {
+			return fmt.Sprintf("must not exceed %d bytes", max)
+		}
+
+		return ""
+	}
+}
+
+// Range - integer field must be within [min, max]
+func Range(min, max int64) Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		if n := v.Int(); n < min || n > max {
+			return fmt.Sprintf("must be between %d and %d", min, max)
+		}
+
+		return ""
+	}
+}
+
+// OneOf - string field, when set, must be one of values
+func OneOf(values ...string) Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		if !set {
+			return ""
+		}
+
+		for _, value := range values {
+
+			if v.String() == value {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("must be one of %s", strings.Join(values, ", "))
+	}
+}
+
+// ObjectID - string field must be a valid hex encoded mongo ObjectID
+func ObjectID() Rule {
+
+	return func(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) string {
+
+		if _, err := primitive.ObjectIDFromHex(v.String()); err != nil {
+			return "must be a 24 character hex ObjectID"
+		}
+
+		return ""
+	}
+}
+
+// validationError - InvalidArgument status listing every violation
+func validationError(violations []*errdetails.BadRequest_FieldViolation) error {
+
+	fields := make([]string, 0, len(violations))
+
+	for _, v := range violations {
+		fields = append(fields, v.GetField()+" "+v.GetDescription())
+	}
+
+	st := status.New(codes.InvalidArgument, "invalid request : "+strings.Join(fields, "; "))
+
+	ds, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+
+	if err != nil {
+		return st.Err()
+	}
+
+	return ds.Err()
+}
+
+// UnaryValidator - rejects unary requests that violate their rules before the handler runs
+func UnaryValidator() grpc.UnaryServerInterceptor {
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		if msg, ok := req.(proto.Message); ok {
+
+			if violations := Validate(msg); len(violations) > 0 {
+				return nil, validationError(violations)
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidator - validates every message received on a stream
+func StreamValidator() grpc.StreamServerInterceptor {
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		return handler(srv, &validatingStream{ServerStream: ss})
+	}
+}
+
+// validatingStream - wraps a server stream and validates incoming messages
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+// RecvMsg -
+func (s *validatingStream) RecvMsg(m interface{}) error {
+
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if msg, ok := m.(proto.Message); ok {
+
+		if violations := Validate(msg); len(violations) > 0 {
+			return validationError(violations)
+		}
+	}
+
+	return nil
+}
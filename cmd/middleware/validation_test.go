@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	blogpb "grpcourse/data/protos/blog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+
+	Register(&blogpb.UpdateBlogRequest{}, Rules{
+		"blog":       {Required()},
+		"blog.id":    {Required(), ObjectID()},
+		"blog.title": {Required(), Length(1, 10)},
+		"image":      {MaxBytes(4)},
+	})
+
+	Register(&blogpb.CreateBlogRequest{}, Rules{
+		"blog.title": {Required()},
+	})
+
+	Register(&blogpb.DownloadImageRequest{}, Rules{
+		"variant": {OneOf("small", "large")},
+	})
+}
+
+func TestValidate(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		req    *blogpb.UpdateBlogRequest
+		fields []string
+	}{
+		{"missing blog", &blogpb.UpdateBlogRequest{}, []string{"blog"}},
+		{"bad id", &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: "xyz", Title: "ok"}}, []string{"blog.id"}},
+		{"long title and image", &blogpb.UpdateBlogRequest{
+			Blog:  &blogpb.Blog{Id: "5f2011c0f7bc9e1a387c2a1e", Title: "a very long title"},
+			Image: []byte("12345"),
+		}, []string{"blog.title", "image"}},
+		{"valid", &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: "5f2011c0f7bc9e1a387c2a1e", Title: "ok"}}, nil},
+	}
+
+	for _, c := range cases {
+
+		violations := Validate(c.req)
+
+		if len(violations) != len(c.fields) {
+			t.Fatalf("%s : expected %v violations got %v", c.name, c.fields, violations)
+		}
+
+		for i, v := range violations {
+			if v.GetField() != c.fields[i] {
+				t.Errorf("%s : expected field %s got %s", c.name, c.fields[i], v.GetField())
+			}
+		}
+	}
+}
+
+func TestRegisterChecksPaths(t *testing.T) {
+
+	for _, path := range []string{"blog.titel", "nope", "image.size", "blog.title.x"} {
+
+		func() {
+
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%v : expected Register to panic", path)
+				}
+			}()
+
+			Register(&blogpb.UpdateBlogRequest{}, Rules{path: {Required()}})
+		}()
+	}
+
+	// the rules registered in init are still in place
+	if violations := Validate(&blogpb.UpdateBlogRequest{}); len(violations) != 1 || violations[0].GetField() != "blog" {
+		t.Errorf("unexpected violations : %v", violations)
+	}
+}
+
+func TestValidateSkipsUnsetOneof(t *testing.T) {
+
+	chunk := &blogpb.CreateBlogRequest{Data: &blogpb.CreateBlogRequest_Image{Image: []byte{1}}}
+
+	if violations := Validate(chunk); len(violations) != 0 {
+		t.Errorf("image chunk should not be checked against blog rules : %v", violations)
+	}
+
+	blog := &blogpb.CreateBlogRequest{Data: &blogpb.CreateBlogRequest_Blog{Blog: &blogpb.Blog{}}}
+
+	if violations := Validate(blog); len(violations) != 1 {
+		t.Errorf("expected missing title violation got %v", violations)
+	}
+}
+
+func TestOneOf(t *testing.T) {
+
+	for variant, violations := range map[string]int{"": 0, "small": 0, "huge": 1} {
+
+		if got := Validate(&blogpb.DownloadImageRequest{Variant: variant}); len(got) != violations {
+			t.Errorf("%q : expected %d violations got %v", variant, violations, got)
+		}
+	}
+}
+
+func TestUnaryValidator(t *testing.T) {
+
+	called := false
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return req, nil
+	}
+
+	_, err := UnaryValidator()(context.Background(), &blogpb.UpdateBlogRequest{}, &grpc.UnaryServerInfo{}, handler)
+
+	if called {
+		t.Fatal("handler should not run for an invalid request")
+	}
+
+	st := status.Convert(err)
+
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument got %v", st.Code())
+	}
+
+	if _, ok := st.Details()[0].(*errdetails.BadRequest); !ok {
+		t.Errorf("expected BadRequest details got %v", st.Details())
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/data/db"
+	"grpcourse/data/migrate"
+	"grpcourse/data/tenant"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migrateTimeout - long enough to rewrite every blog of a tenant
+const migrateTimeout = 10 * time.Minute
+
+var migrateTenant string
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply, revert and inspect schema migrations",
+	Long: `Migrations are declared in data/migrate and recorded per database in
+schema_migrations. With tenancy.enabled every tenant database is migrated,
+--tenant limits the command to one of them.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply pending migrations and reconcile indexes",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		to, _ := cmd.Flags().GetInt("to")
+
+		return eachDatabase(func(ctx context.Context, d *mongo.Database) error {
+
+			done, err := migrate.Up(ctx, d, migrate.Migrations, to)
+
+			for _, m := range done {
+				fmt.Printf("%v : applied %d %v\n", d.Name(), m.Version, m.Name)
+			}
+
+			if err != nil {
+				return err
+			}
+
+			return reconcileIndexes(ctx, d, logrus.StandardLogger())
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert the latest migrations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		steps, _ := cmd.Flags().GetInt("steps")
+
+		return eachDatabase(func(ctx context.Context, d *mongo.Database) error {
+
+			done, err := migrate.Down(ctx, d, migrate.Migrations, steps)
+
+			for _, m := range done {
+				fmt.Printf("%v : reverted %d %v\n", d.Name(), m.Version, m.Name)
+			}
+
+			return err
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which migrations are applied",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(w, "DATABASE\tVERSION\tNAME\tAPPLIED")
+
+		err := eachDatabase(func(ctx context.Context, d *mongo.Database) error {
+
+			states, err := migrate.Status(ctx, d, migrate.Migrations)
+
+			for _, st := range states {
+
+				applied := "pending"
+
+				if st.Applied != nil {
+					applied = st.Applied.Format(time.RFC3339)
+				}
+
+				fmt.Fprintf(w, "%v\t%d\t%v\t%v\n", d.Name(), st.Version, st.Name, applied)
+			}
+
+			return err
+		})
+
+		w.Flush()
+
+		return err
+	},
+}
+
+// eachDatabase - runs fn on the shared database, or on every tenant database
+// (just --tenant when set) with tenancy on
+func eachDatabase(fn func(ctx context.Context, d *mongo.Database) error) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	defer cancel()
+
+	databases, err := blogDatabases(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, d := range databases {
+
+		if err := fn(ctx, d); err != nil {
+			return fmt.Errorf("%v : %v", d.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// blogDatabases - the databases holding blogs
+func blogDatabases(ctx context.Context) ([]*mongo.Database, error) {
+
+	if !cfg.Tenancy.Enabled {
+		return []*mongo.Database{db.GetDB()}, nil
+	}
+
+	reg := registry()
+
+	if migrateTenant != "" {
+
+		if !tenant.Valid(migrateTenant) {
+			return nil, fmt.Errorf("%q is not a valid tenant id", migrateTenant)
+		}
+
+		return []*mongo.Database{reg.Database(migrateTenant)}, nil
+	}
+
+	tenants, err := reg.List(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list tenants : %v", err)
+	}
+
+	var databases []*mongo.Database
+
+	for _, t := range tenants {
+		databases = append(databases, reg.Database(t.ID))
+	}
+
+	return databases, nil
+}
+
+// reconcileIndexes - brings the indexes of d in line with migrate.Indexes
+func reconcileIndexes(ctx context.Context, d *mongo.Database, logger *logrus.Logger) error {
+
+	created, dropped, err := migrate.Reconcile(ctx, d, migrate.Indexes)
+
+	for _, name := range created {
+		logger.Infof("%v : created index %v", d.Name(), name)
+	}
+
+	for _, name := range dropped {
+		logger.Infof("%v : dropped index %v", d.Name(), name)
+	}
+
+	return err
+}
+
+// ensureIndexes - creates the indexes of migrate.Indexes missing from d,
+// on every server start. Dropping is left to the migrate command.
+func ensureIndexes(ctx context.Context, d *mongo.Database, logger *logrus.Logger) error {
+
+	created, stale, err := migrate.Ensure(ctx, d, migrate.Indexes)
+
+	for _, name := range created {
+		logger.Infof("%v : created index %v", d.Name(), name)
+	}
+
+	for _, name := range stale {
+		logger.Warnf("%v : index %v differs from the code, run the migrate up command", d.Name(), name)
+	}
+
+	return err
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrateTenant, "tenant", "", "only migrate this tenant (tenancy.enabled)")
+
+	migrateUpCmd.Flags().Int("to", 0, "stop after this version, 0 for the latest")
+	migrateDownCmd.Flags().Int("steps", 1, "number of migrations to revert")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+
+	rootCmd.AddCommand(migrateCmd)
+}
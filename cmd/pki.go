@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"grpcourse/cmd/pki"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pkiDir    string
+	pkiCAPass string
+)
+
+const day = 24 * time.Hour
+
+var pkiCmd = &cobra.Command{
+	Use:   "pki",
+	Short: "Development PKI - create a CA and issue, rotate and inspect certificates",
+	Long: `Manages the TLS material read by the server and client commands:
+
+  ca.crt, ca.key          certificate authority
+  server.crt, server.pem  server certificate and key
+  <name>.crt, <name>.pem  client certificates for mutual TLS`,
+	// no config needed to manage certificates
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+}
+
+var pkiCACmd = &cobra.Command{
+	Use:   "ca",
+	Short: "Create the certificate authority",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cn, _ := cmd.Flags().GetString("cn")
+		force, _ := cmd.Flags().GetBool("force")
+
+		ca, err := pki.CreateCA(pkiDir, cn, validity(cmd), force)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("CA %q created, expires %v\n", ca.Cert.Subject.CommonName, ca.Cert.NotAfter.Format(time.RFC3339))
+
+		return nil
+	},
+}
+
+var pkiServerCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Issue the server certificate",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		cn, _ := cmd.Flags().GetString("cn")
+		sans, _ := cmd.Flags().GetStringSlice("san")
+
+		return issue(pki.Request{
+			Name:       pki.ServerName,
+			CommonName: cn,
+			SANs:       sans,
+			Validity:   validity(cmd),
+		})
+	},
+}
+
+var pkiClientCmd = &cobra.Command{
+	Use:   "client",
+	Short: "Issue a client certificate for mutual TLS",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		name, _ := cmd.Flags().GetString("name")
+		cn, _ := cmd.Flags().GetString("cn")
+		sans, _ := cmd.Flags().GetStringSlice("san")
+
+		return issue(pki.Request{
+			Name:       name,
+			CommonName: cn,
+			SANs:       sans,
+			Client:     true,
+			Validity:   validity(cmd),
+		})
+	},
+}
+
+var pkiRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Re-issue every certificate with fresh keys (and optionally a new CA)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		var (
+			ca  *pki.CA
+			err error
+		)
+
+		if withCA, _ := cmd.Flags().GetBool("ca"); withCA {
+
+			old, err := pki.ReadCert(filepath.Join(pkiDir, pki.CACert))
+
+			if err != nil {
+				return err
+			}
+
+			ca, err = pki.CreateCA(pkiDir, old.Subject.CommonName, old.NotAfter.Sub(old.NotBefore), true)
+
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("rotated CA - distribute the new ca.crt to every client")
+
+		} else if ca, err = pki.LoadCA(pkiDir, pkiCAPass); err != nil {
+			return err
+		}
+
+		rotated, err := ca.Rotate(pkiDir, validity(cmd))
+
+		for _, name := range rotated {
+			fmt.Printf("rotated %v\n", pki.CertFile(pkiDir, name))
+		}
+
+		return err
+	},
+}
+
+var pkiStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show expiry of the certificates on disk",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		infos, err := pki.Status(pkiDir)
+
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(w, "NAME\tSUBJECT\tSANS\tEXPIRES\tDAYS LEFT")
+
+		for _, i := range infos {
+
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%d\n",
+				i.Name,
+				i.Cert.Subject.CommonName,
+				len(i.Cert.DNSNames)+len(i.Cert.IPAddresses)+len(i.Cert.URIs),
+				i.Cert.NotAfter.Format("2006-01-02"),
+				int(i.ExpiresIn().Hours()/24),
+			)
+		}
+
+		return w.Flush()
+	},
+}
+
+// validity - the --days flag of cmd as a duration
+func validity(cmd *cobra.Command) time.Duration {
+
+	days, _ := cmd.Flags().GetInt("days")
+
+	return time.Duration(days) * day
+}
+
+// issue - signs req with the CA on disk
+func issue(req pki.Request) error {
+
+	ca, err := pki.LoadCA(pkiDir, pkiCAPass)
+
+	if err != nil {
+		return err
+	}
+
+	cert, err := ca.Issue(pkiDir, req)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("issued %v (%v), expires %v\n", pki.CertFile(pkiDir, req.Name), cert.Subject.CommonName, cert.NotAfter.Format(time.RFC3339))
+
+	return nil
+}
+
+func init() {
+	pkiCmd.PersistentFlags().StringVar(&pkiDir, "dir", "ssl", "directory holding the certificates")
+	pkiCmd.PersistentFlags().StringVar(&pkiCAPass, "ca-pass", "1111", "passphrase of a legacy encrypted ca.key")
+
+	pkiCACmd.Flags().String("cn", "grpcourse-dev-ca", "common name of the CA")
+	pkiCACmd.Flags().Int("days", 3650, "validity in days")
+	pkiCACmd.Flags().Bool("force", false, "replace an existing CA")
+
+	pkiServerCmd.Flags().String("cn", "localhost", "common name of the server")
+	pkiServerCmd.Flags().StringSlice("san", []string{"localhost", "127.0.0.1", "::1"}, "DNS names, IPs or URIs the certificate is valid for")
+	pkiServerCmd.Flags().Int("days", 365, "validity in days")
+
+	pkiClientCmd.Flags().String("name", "client", "file name of the certificate (<name>.crt, <name>.pem)")
+	pkiClientCmd.Flags().String("cn", "blog-client", "common name, used as the caller identity")
+	pkiClientCmd.Flags().StringSlice("san", nil, "additional identities eg. spiffe://grpcourse/blog-client")
+	pkiClientCmd.Flags().Int("days", 365, "validity in days")
+
+	pkiRotateCmd.Flags().Int("days", 365, "validity in days of the re-issued certificates")
+	pkiRotateCmd.Flags().Bool("ca", false, "also replace the CA")
+
+	pkiCmd.AddCommand(pkiCACmd, pkiServerCmd, pkiClientCmd, pkiRotateCmd, pkiStatusCmd)
+
+	rootCmd.AddCommand(pkiCmd)
+}
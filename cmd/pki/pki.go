@@ -0,0 +1,457 @@
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File layout inside the PKI directory - the server and client commands read
+// the same files (see config.TLS and the gc flags)
+const (
+	CACert     = "ca.crt"
+	CAKey      = "ca.key"
+	ServerName = "server"
+)
+
+// CertFile - certificate path of a named leaf
+func CertFile(dir, name string) string { return filepath.Join(dir, name+".crt") }
+
+// KeyFile - private keys are stored unencrypted as PKCS#8 with a .pem extension
+func KeyFile(dir, name string) string { return filepath.Join(dir, name+".pem") }
+
+// Request - what to put in a leaf certificate
+type Request struct {
+	// Name - file name of the certificate and key inside the directory
+	Name string
+
+	// CommonName - subject CN, used as the caller identity for client certificates
+	CommonName string
+
+	// SANs - DNS names, IP addresses or URIs (eg. spiffe://grpcourse/blog-client)
+	SANs []string
+
+	// Client - issue a clientAuth certificate instead of a serverAuth one
+	Client bool
+
+	Validity time.Duration
+}
+
+// CA - a loaded certificate authority
+type CA struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// CreateCA - generates a self-signed CA in dir. Existing CA files are only
+// replaced when force is set since every issued certificate depends on them.
+func CreateCA(dir, cn string, validity time.Duration, force bool) (*CA, error) {
+
+	if _, err := os.Stat(filepath.Join(dir, CACert)); err == nil && !force {
+		return nil, fmt.Errorf("%v already exists, pass force to replace it", filepath.Join(dir, CACert))
+	}
+
+	key, err := newKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerial()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             now.Add(-5 * time.Minute),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create CA certificate : %v", err)
+	}
+
+	if err := writeFiles(dir, filepath.Join(dir, CACert), filepath.Join(dir, CAKey), der, key); err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{Cert: cert, Key: key}, nil
+}
+
+// LoadCA - reads the CA certificate and key from dir. pass decrypts legacy
+// password protected keys (eg. those produced by openssl -des3).
+func LoadCA(dir, pass string) (*CA, error) {
+
+	cert, err := ReadCert(filepath.Join(dir, CACert))
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := readKey(filepath.Join(dir, CAKey), pass)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &CA{Cert: cert, Key: key}, nil
+}
+
+// Issue - generates a key and a certificate signed by the CA and writes
+// both to dir. An existing certificate with the same name is replaced.
+func (ca *CA) Issue(dir string, req Request) (*x509.Certificate, error) {
+
+	if req.Name == "" || strings.ContainsAny(req.Name, `/\`) {
+		return nil, fmt.Errorf("invalid certificate name %q", req.Name)
+	}
+
+	key, err := newKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSerial()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: req.CommonName},
+		NotBefore:    now.Add(-5 * time.Minute),
+		NotAfter:     now.Add(req.Validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	if req.Client {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	// never outlive the CA - clients would reject the chain anyway
+	if tmpl.NotAfter.After(ca.Cert.NotAfter) {
+		tmpl.NotAfter = ca.Cert.NotAfter
+	}
+
+	if err := addSANs(tmpl, req.SANs); err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.Key)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create certificate %v : %v", req.Name, err)
+	}
+
+	if err := writeFiles(dir, CertFile(dir, req.Name), KeyFile(dir, req.Name), der, key); err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// Rotate - re-issues every leaf certificate in dir with a fresh key, keeping
+// its subject, SANs and usage. The certificates are valid for validity.
+func (ca *CA) Rotate(dir string, validity time.Duration) ([]string, error) {
+
+	infos, err := Status(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rotated []string
+
+	for _, info := range infos {
+
+		if info.Name == strings.TrimSuffix(CACert, ".crt") {
+			continue
+		}
+
+		req := Request{
+			Name:       info.Name,
+			CommonName: info.Cert.Subject.CommonName,
+			SANs:       sans(info.Cert),
+			Client:     isClient(info.Cert),
+			Validity:   validity,
+		}
+
+		// certificates from the old openssl script only carry a CN, which
+		// go clients no longer accept for hostname verification
+		if len(req.SANs) == 0 && !req.Client && req.CommonName != "" {
+			req.SANs = []string{req.CommonName}
+		}
+
+		if _, err := ca.Issue(dir, req); err != nil {
+			return rotated, err
+		}
+
+		rotated = append(rotated, info.Name)
+	}
+
+	return rotated, nil
+}
+
+// Info - a certificate found on disk
+type Info struct {
+	Name string
+	Path string
+	Cert *x509.Certificate
+}
+
+// ExpiresIn - time left before the certificate expires
+func (i Info) ExpiresIn() time.Duration {
+	return time.Until(i.Cert.NotAfter)
+}
+
+// Status - parses every *.crt file in dir, soonest expiry first
+func Status(dir string) ([]Info, error) {
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.crt"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []Info
+
+	for _, p := range paths {
+
+		cert, err := ReadCert(p)
+
+		if err != nil {
+			return nil, err
+		}
+
+		infos = append(infos, Info{
+			Name: strings.TrimSuffix(filepath.Base(p), ".crt"),
+			Path: p,
+			Cert: cert,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Cert.NotAfter.Before(infos[j].Cert.NotAfter)
+	})
+
+	return infos, nil
+}
+
+// ReadCert - parses the first PEM certificate in path
+func ReadCert(path string) (*x509.Certificate, error) {
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read certificate : %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found in %v", path)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// readKey - parses a PKCS#8, PKCS#1 or SEC1 private key, decrypting legacy
+// encrypted PEM blocks with pass
+func readKey(path, pass string) (crypto.Signer, error) {
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read private key : %v", err)
+	}
+
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("no PEM private key found in %v", path)
+	}
+
+	der := block.Bytes
+
+	// legacy openssl keys are DES3 encrypted PEM
+	if x509.IsEncryptedPEMBlock(block) {
+
+		if der, err = x509.DecryptPEMBlock(block, []byte(pass)); err != nil {
+			return nil, fmt.Errorf("cannot decrypt %v : %v", path, err)
+		}
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key in %v", path)
+}
+
+// writeFiles - writes the certificate (0644) and its key (0600) as PEM
+func writeFiles(dir, certPath, keyPath string, der []byte, key crypto.Signer) error {
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	// key first - a certificate without its key on disk is useless
+	if err := writeAtomic(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600); err != nil {
+		return err
+	}
+
+	return writeAtomic(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// writeAtomic - writes to a temp file and renames it so readers (eg. a server
+// reloading its certificate) never see a partially written file
+func writeAtomic(path string, data []byte, mode os.FileMode) error {
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// addSANs - sorts each SAN into DNS names, IP addresses and URIs
+func addSANs(tmpl *x509.Certificate, values []string) error {
+
+	for _, v := range values {
+
+		if ip := net.ParseIP(v); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+			continue
+		}
+
+		if strings.Contains(v, "://") {
+
+			u, err := url.Parse(v)
+
+			if err != nil {
+				return fmt.Errorf("invalid URI SAN %q : %v", v, err)
+			}
+
+			tmpl.URIs = append(tmpl.URIs, u)
+			continue
+		}
+
+		if v == "" {
+			return errors.New("empty SAN")
+		}
+
+		tmpl.DNSNames = append(tmpl.DNSNames, v)
+	}
+
+	return nil
+}
+
+// sans - the SANs of cert in the form accepted by addSANs
+func sans(cert *x509.Certificate) []string {
+
+	var values []string
+
+	values = append(values, cert.DNSNames...)
+
+	for _, ip := range cert.IPAddresses {
+		values = append(values, ip.String())
+	}
+
+	for _, u := range cert.URIs {
+		values = append(values, u.String())
+	}
+
+	return values
+}
+
+// isClient - reports whether cert is a client (clientAuth only) certificate
+func isClient(cert *x509.Certificate) bool {
+
+	for _, u := range cert.ExtKeyUsage {
+
+		if u == x509.ExtKeyUsageServerAuth {
+			return false
+		}
+	}
+
+	return len(cert.ExtKeyUsage) > 0
+}
+
+func newKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+func newSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
@@ -0,0 +1,130 @@
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssueAndVerify(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "pki")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	ca, err := CreateCA(dir, "test-ca", 24*time.Hour, false)
+
+	if err != nil {
+		t.Fatalf("cannot create CA : %v", err)
+	}
+
+	if _, err := CreateCA(dir, "test-ca", 24*time.Hour, false); err == nil {
+		t.Error("an existing CA should not be replaced without force")
+	}
+
+	if _, err := ca.Issue(dir, Request{Name: ServerName, CommonName: "localhost", SANs: []string{"localhost", "127.0.0.1"}, Validity: time.Hour}); err != nil {
+		t.Fatalf("cannot issue server certificate : %v", err)
+	}
+
+	// validity is capped by the CA
+	client, err := ca.Issue(dir, Request{Name: "client", CommonName: "blog-client", SANs: []string{"spiffe://grpcourse/blog-client"}, Client: true, Validity: 48 * time.Hour})
+
+	if err != nil {
+		t.Fatalf("cannot issue client certificate : %v", err)
+	}
+
+	if client.NotAfter.After(ca.Cert.NotAfter) {
+		t.Errorf("client certificate outlives the CA")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Cert)
+
+	server, err := ReadCert(CertFile(dir, ServerName))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := server.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots}); err != nil {
+		t.Errorf("server certificate does not verify : %v", err)
+	}
+
+	if _, err := client.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("client certificate does not verify : %v", err)
+	}
+
+	infos, err := Status(dir)
+
+	// soonest expiry first
+	if err != nil || len(infos) != 3 || infos[0].Name != ServerName {
+		t.Fatalf("unexpected status %+v : %v", infos, err)
+	}
+
+	// rotation keeps the identities but replaces the keys
+	rotated, err := ca.Rotate(dir, time.Hour)
+
+	if err != nil || len(rotated) != 2 {
+		t.Fatalf("unexpected rotation %v : %v", rotated, err)
+	}
+
+	again, _ := ReadCert(CertFile(dir, "client"))
+
+	if again.SerialNumber.Cmp(client.SerialNumber) == 0 || len(again.URIs) != 1 || !isClient(again) {
+		t.Errorf("client certificate was not re-issued with the same identity")
+	}
+}
+
+func TestLoadLegacyEncryptedCA(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "pki")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	ca, err := CreateCA(dir, "legacy-ca", time.Hour, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replace the key with a DES3 encrypted RSA key like the old openssl script made
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+
+	block, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key), []byte("1111"), x509.PEMCipher3DES)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, CAKey), pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadCA(dir, "1111")
+
+	if err != nil {
+		t.Fatalf("cannot load encrypted key : %v", err)
+	}
+
+	if loaded.Cert.Subject.CommonName != ca.Cert.Subject.CommonName {
+		t.Errorf("unexpected CA %v", loaded.Cert.Subject)
+	}
+
+	if _, err := LoadCA(dir, "wrong"); err == nil {
+		t.Error("expected an error with the wrong passphrase")
+	}
+}
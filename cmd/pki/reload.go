@@ -0,0 +1,198 @@
+package pki
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// expiryWarning - log at warning level when a certificate gets this close to expiry
+const expiryWarning = 30 * 24 * time.Hour
+
+// Reloader - serves a certificate/key pair from disk through tls.Config.GetCertificate
+// and reloads it when the files change. A failed reload keeps the last good pair.
+type Reloader struct {
+	certPath string
+	keyPath  string
+	logger   *logrus.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	leaf     *x509.Certificate
+	modTime  time.Time
+	failed   time.Time
+	reported time.Time
+
+	// OnLoad - called with the leaf certificate after every successful load
+	OnLoad func(leaf *x509.Certificate)
+}
+
+// NewReloader - loads the pair once, failing if it cannot be used
+func NewReloader(certPath, keyPath string, logger *logrus.Logger) (*Reloader, error) {
+
+	r := &Reloader{certPath: certPath, keyPath: keyPath, logger: logger}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate - tls.Config hook returning the current certificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// Leaf - the parsed certificate currently served
+func (r *Reloader) Leaf() *x509.Certificate {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.leaf
+}
+
+// Reload - reads the pair from disk. On error the previous certificate stays in use.
+func (r *Reloader) Reload() error {
+
+	modTime, err := r.lastModified()
+
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+
+	if err != nil {
+		return fmt.Errorf("cannot load certificate %v : %v", r.certPath, err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+
+	if err != nil {
+		return fmt.Errorf("cannot parse certificate %v : %v", r.certPath, err)
+	}
+
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("certificate %v expired on %v", r.certPath, leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	cert.Leaf = leaf
+
+	r.mu.Lock()
+	r.cert, r.leaf, r.modTime = &cert, leaf, modTime
+	r.mu.Unlock()
+
+	r.logger.Infof("loaded certificate %v (%v)", r.certPath, leaf.Subject.CommonName)
+	r.logExpiry()
+
+	if r.OnLoad != nil {
+		r.OnLoad(leaf)
+	}
+
+	return nil
+}
+
+// Watch - polls the files every interval and reloads when they change,
+// also reporting days until expiry. It returns when ctx is done.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+
+			modTime, err := r.lastModified()
+
+			if err != nil {
+				r.logger.Errorf("cannot stat certificate : %v", err)
+				continue
+			}
+
+			// skip files that already failed to load until they change again
+			r.mu.RLock()
+			changed := !modTime.Equal(r.modTime) && !modTime.Equal(r.failed)
+			r.mu.RUnlock()
+
+			if !changed {
+
+				// once a day is plenty for an unchanged certificate
+				r.mu.RLock()
+				due := time.Since(r.reported) > 24*time.Hour
+				r.mu.RUnlock()
+
+				if due {
+					r.logExpiry()
+				}
+
+				continue
+			}
+
+			if err := r.Reload(); err != nil {
+
+				r.logger.Errorf("certificate reload failed, keeping the current one : %v", err)
+
+				r.mu.Lock()
+				r.failed = modTime
+				r.mu.Unlock()
+			}
+		}
+	}
+}
+
+// logExpiry - logs days until the current certificate expires, as a warning when close
+func (r *Reloader) logExpiry() {
+
+	r.mu.Lock()
+	leaf := r.leaf
+	r.reported = time.Now()
+	r.mu.Unlock()
+
+	left := time.Until(leaf.NotAfter)
+
+	entry := r.logger.WithField("days_until_expiry", int(left.Hours()/24)).WithField("certificate", r.certPath)
+
+	if left < expiryWarning {
+		entry.Warnf("certificate expires soon - rotate it with the pki command")
+		return
+	}
+
+	entry.Infof("certificate expiry")
+}
+
+// lastModified - latest modification time of the cert and key files
+func (r *Reloader) lastModified() (time.Time, error) {
+
+	var latest time.Time
+
+	for _, p := range []string{r.certPath, r.keyPath} {
+
+		fi, err := os.Stat(p)
+
+		if err != nil {
+			return latest, err
+		}
+
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,76 @@
+package pki
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestReloader(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "pki")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	ca, err := CreateCA(dir, "test-ca", 24*time.Hour, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := ca.Issue(dir, Request{Name: ServerName, CommonName: "localhost", SANs: []string{"localhost"}, Validity: time.Hour})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewReloader(CertFile(dir, "missing"), KeyFile(dir, "missing"), logrus.New()); err == nil {
+		t.Fatal("expected startup to fail without a certificate")
+	}
+
+	r, err := NewReloader(CertFile(dir, ServerName), KeyFile(dir, ServerName), logrus.New())
+
+	if err != nil {
+		t.Fatalf("cannot load certificate : %v", err)
+	}
+
+	loaded := 0
+	r.OnLoad = func(*x509.Certificate) { loaded++ }
+
+	second, err := ca.Issue(dir, Request{Name: ServerName, CommonName: "localhost", SANs: []string{"localhost"}, Validity: time.Hour})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Reload(); err != nil {
+		t.Fatalf("cannot reload : %v", err)
+	}
+
+	if r.Leaf().SerialNumber.Cmp(second.SerialNumber) != 0 || loaded != 1 {
+		t.Fatalf("expected the re-issued certificate to be served")
+	}
+
+	// a broken file must not replace the working certificate
+	if err := ioutil.WriteFile(CertFile(dir, ServerName), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Reload(); err == nil {
+		t.Fatal("expected reload of a broken certificate to fail")
+	}
+
+	cert, _ := r.GetCertificate(nil)
+
+	if cert == nil || cert.Leaf.SerialNumber.Cmp(second.SerialNumber) != 0 || first.SerialNumber.Cmp(second.SerialNumber) == 0 {
+		t.Errorf("last good certificate should still be served")
+	}
+}
@@ -1,14 +1,29 @@
 package cmd
 
 import (
+	"grpcourse/config"
+
 	"github.com/spf13/cobra"
 )
 
+var (
+	cfgFile string
+	cfg     *config.Config
+)
+
 var rootCmd = &cobra.Command{
 	Use:     "Pipeline",
 	Aliases: []string{"serve"},
 	Short:   "Backend Service App",
 	Long:    `Pipeline is a simple golang app complemented with CI/CD pipeline.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
+		cfg, err = config.Load(cfgFile)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "config.json", "path to the JSON config file")
 }
 
 // Execute executes the root command.
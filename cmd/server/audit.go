@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"grpcourse/cmd/middleware"
+	"grpcourse/data/store"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// auditTimeout - the record is written even when the caller has gone away
+const auditTimeout = 5 * time.Second
+
+// auditEvent - collects what a mutation did, written once it returns:
+//
+//	ev := b.startAudit(ctx, "UpdateBlog")
+//	defer func() { ev.finish(err) }()
+type auditEvent struct {
+	b     *Server
+	store store.Store
+	rec   store.AuditRecord
+}
+
+// startAudit - records who is calling, before anything can fail
+func (b *Server) startAudit(ctx context.Context, action string) *auditEvent {
+
+	rec := store.AuditRecord{Time: time.Now().UTC(), Action: action, RequestID: middleware.RequestIDFromContext(ctx)}
+
+	if id, ok := middleware.IdentityFromContext(ctx); ok {
+		rec.Actor, rec.Certificate = id.Subject, id.Certificate
+	}
+
+	if pr, ok := peer.FromContext(ctx); ok && pr.Addr != nil {
+		rec.Peer = pr.Addr.String()
+	}
+
+	// the trail is kept with the tenant's blogs, nil when there is no tenant
+	st, _ := b.blogStore(ctx)
+
+	return &auditEvent{b: b, store: st, rec: rec}
+}
+
+// blog - the blog acted on
+func (e *auditEvent) blog(oid primitive.ObjectID) {
+	e.rec.BlogID = oid.Hex()
+}
+
+// before, after - the blog fields around the change, stored as hashes
+func (e *auditEvent) before(item *blogItem) {
+	e.rec.Before = fieldHashes(item)
+}
+
+func (e *auditEvent) after(item *blogItem) {
+	e.rec.After = fieldHashes(item)
+}
+
+// finish - appends the record with the outcome of the call. A failed write
+// is logged, the mutation it describes has already happened.
+func (e *auditEvent) finish(err error) {
+
+	st := status.Convert(err)
+
+	e.rec.Outcome = st.Code().String()
+
+	if err != nil {
+		e.rec.Error = st.Message()
+	}
+
+	if e.store == nil {
+		e.b.Logger.WithField("audit", e.rec).Errorf("cannot write audit record : no tenant")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+
+	if err := e.store.AppendAudit(ctx, &e.rec); err != nil {
+		e.b.Logger.WithField("audit", e.rec).Errorf("cannot write audit record : %v", err)
+	}
+}
+
+// fieldHashes - sha256 of every field, enough to tell what changed without
+// copying blog content into the audit trail
+func fieldHashes(item *blogItem) map[string]string {
+
+	if item == nil {
+		return nil
+	}
+
+	return map[string]string{
+		"author_id": hash(item.AuthorID),
+		"title":     hash(item.Title),
+		"body":      hash(item.Body),
+		"image":     hash(item.CoverImage),
+	}
+}
+
+func hash(v string) string {
+
+	sum := sha256.Sum256([]byte(v))
+
+	return hex.EncodeToString(sum[:])
+}
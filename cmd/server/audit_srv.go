@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+	auditpb "grpcourse/data/protos/audit"
+	"grpcourse/data/store"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// audit listing page sizes
+const (
+	defaultAuditPage = 50
+	maxAuditPage     = 500
+)
+
+// ListAuditEvents - audit records newest first, filtered by actor, blog and time
+func (b *Server) ListAuditEvents(ctx context.Context, req *auditpb.ListAuditEventsRequest) (*auditpb.ListAuditEventsResponse, error) {
+
+	b.Logger.Infof("ListAuditEvents func invoked")
+
+	// 1. admins only - the trail covers every author
+	if b.Config.Auth.Enabled {
+
+		id, ok := middleware.IdentityFromContext(ctx)
+
+		if !ok {
+			return nil, status.Errorf(codes.Unauthenticated, "authentication required")
+		}
+
+		if !id.Admin {
+			return nil, status.Errorf(codes.PermissionDenied, "%v may not read the audit trail", id.Subject)
+		}
+	}
+
+	st, err := b.blogStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. filters
+	q := store.AuditQuery{Actor: req.GetActor()}
+
+	if req.GetBlogId() != "" {
+
+		if _, err := primitive.ObjectIDFromHex(req.GetBlogId()); err != nil {
+			return nil, invalidArgument("blog_id", "must be a 24 character hex ObjectID")
+		}
+
+		q.BlogID = req.GetBlogId()
+	}
+
+	if req.GetSince() != nil {
+
+		if q.Since, err = ptypes.Timestamp(req.GetSince()); err != nil {
+			return nil, invalidArgument("since", err.Error())
+		}
+	}
+
+	if req.GetUntil() != nil {
+
+		if q.Until, err = ptypes.Timestamp(req.GetUntil()); err != nil {
+			return nil, invalidArgument("until", err.Error())
+		}
+	}
+
+	// page tokens are the id of the last record returned, ids grow with time
+	if req.GetPageToken() != "" {
+
+		if q.Before, err = primitive.ObjectIDFromHex(req.GetPageToken()); err != nil {
+			return nil, invalidArgument("page_token", "must be the next_page_token of a previous response")
+		}
+	}
+
+	size := int(req.GetPageSize())
+
+	if size <= 0 {
+		size = defaultAuditPage
+	}
+
+	if size > maxAuditPage {
+		size = maxAuditPage
+	}
+
+	// 3. one more than asked tells whether there is a next page
+	q.Limit = size + 1
+
+	records, err := st.ListAudit(ctx, q)
+
+	if err != nil {
+		b.Logger.Errorf("could not fetch audit events : %v", err)
+		return nil, storeError(err, "audit_event", "")
+	}
+
+	res := new(auditpb.ListAuditEventsResponse)
+
+	if len(records) > size {
+		records = records[:size]
+		res.NextPageToken = records[size-1].ID.Hex()
+	}
+
+	for _, r := range records {
+
+		ts, _ := ptypes.TimestampProto(r.Time)
+
+		res.Events = append(res.Events, &auditpb.AuditEvent{
+			Id:          r.ID.Hex(),
+			Time:        ts,
+			Action:      r.Action,
+			BlogId:      r.BlogID,
+			Actor:       r.Actor,
+			Certificate: r.Certificate,
+			Peer:        r.Peer,
+			RequestId:   r.RequestID,
+			Outcome:     r.Outcome,
+			Error:       r.Error,
+			Before:      r.Before,
+			After:       r.After,
+		})
+	}
+
+	return res, nil
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+	"grpcourse/config"
+	"grpcourse/data/media"
+	auditpb "grpcourse/data/protos/audit"
+	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/store"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditTrail(t *testing.T) {
+
+	cfg := config.Default()
+	cfg.Auth.SigningKey = "test"
+
+	st, err := store.NewBolt(filepath.Join(t.TempDir(), "audit.db"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { st.Close(context.Background()) })
+
+	svr := NewServer(cfg, st)
+	svr.Logger.SetLevel(logrus.PanicLevel)
+	svr.Images = media.NewDisk(t.TempDir())
+
+	auth := middleware.NewAuthenticator(cfg.Auth)
+
+	conn := serve(t, svr, grpc.ChainUnaryInterceptor(auth.Unary()), grpc.ChainStreamInterceptor(auth.Stream()))
+
+	blogs, audit := blogpb.NewBlogServiceClient(conn), auditpb.NewAuditServiceClient(conn)
+
+	as := func(subject string, admin bool) context.Context {
+
+		token, err := auth.Issue(subject, admin, time.Minute)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	alice, bob, admin := as("1001", false), as("1002", false), as("9000", true)
+
+	// 1. create, a denied update, an update and a delete
+	stream, err := blogs.CreateBlog(alice)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream.Send(&blogpb.CreateBlogRequest{Data: &blogpb.CreateBlogRequest_Blog{Blog: &blogpb.Blog{Title: "first", Body: "hello"}}})
+
+	created, err := stream.CloseAndRecv()
+
+	if err != nil {
+		t.Fatalf("cannot create : %v", err)
+	}
+
+	id := created.GetBlog().GetId()
+
+	if _, err := blogs.UpdateBlog(bob, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: id, Title: "mine now"}}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected bob to be denied, got %v", err)
+	}
+
+	if _, err := blogs.UpdateBlog(alice, &blogpb.UpdateBlogRequest{Blog: &blogpb.Blog{Id: id, Title: "second", Body: "hello"}}); err != nil {
+		t.Fatalf("cannot update : %v", err)
+	}
+
+	if _, err := blogs.DeleteBlog(alice, &blogpb.DeleteBlogRequest{Id: id}); err != nil {
+		t.Fatalf("cannot delete : %v", err)
+	}
+
+	// 2. admins only
+	if _, err := audit.ListAuditEvents(alice, &auditpb.ListAuditEventsRequest{}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected a non admin to be denied, got %v", err)
+	}
+
+	if _, err := audit.ListAuditEvents(context.Background(), &auditpb.ListAuditEventsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected an anonymous caller to be refused, got %v", err)
+	}
+
+	// 3. two pages of two, newest first
+	var events []*auditpb.AuditEvent
+
+	req := &auditpb.ListAuditEventsRequest{PageSize: 2}
+
+	for pages := 0; ; pages++ {
+
+		res, err := audit.ListAuditEvents(admin, req)
+
+		if err != nil {
+			t.Fatalf("cannot list : %v", err)
+		}
+
+		if pages > 2 || len(res.GetEvents()) > 2 {
+			t.Fatalf("unexpected page %v of %d events", pages, len(res.GetEvents()))
+		}
+
+		events = append(events, res.GetEvents()...)
+
+		if res.GetNextPageToken() == "" {
+			break
+		}
+
+		req.PageToken = res.GetNextPageToken()
+	}
+
+	want := []struct {
+		action, actor string
+		outcome       codes.Code
+	}{
+		{"DeleteBlog", "1001", codes.OK},
+		{"UpdateBlog", "1001", codes.OK},
+		{"UpdateBlog", "1002", codes.PermissionDenied},
+		{"CreateBlog", "1001", codes.OK},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+
+	for i, w := range want {
+
+		ev := events[i]
+
+		if ev.GetAction() != w.action || ev.GetActor() != w.actor || ev.GetOutcome() != w.outcome.String() || ev.GetBlogId() != id {
+			t.Errorf("event %d : expected %v by %v %v, got %v by %v %v on %v", i, w.action, w.actor, w.outcome, ev.GetAction(), ev.GetActor(), ev.GetOutcome(), ev.GetBlogId())
+		}
+	}
+
+	// 4. hashes of the fields around each change, never the content
+	del, upd, denied, create := events[0], events[1], events[2], events[3]
+
+	if create.GetBefore() != nil || create.GetAfter()["title"] != hash("first") || create.GetAfter()["author_id"] != hash("1001") {
+		t.Errorf("unexpected create hashes %v -> %v", create.GetBefore(), create.GetAfter())
+	}
+
+	if denied.GetAfter() != nil || denied.GetError() == "" {
+		t.Errorf("a denied update changed nothing : %v, error %q", denied.GetAfter(), denied.GetError())
+	}
+
+	if upd.GetBefore()["title"] != hash("first") || upd.GetAfter()["title"] != hash("second") || upd.GetBefore()["body"] != upd.GetAfter()["body"] {
+		t.Errorf("unexpected update hashes %v -> %v", upd.GetBefore(), upd.GetAfter())
+	}
+
+	if del.GetBefore()["title"] != hash("second") || del.GetAfter() != nil {
+		t.Errorf("unexpected delete hashes %v -> %v", del.GetBefore(), del.GetAfter())
+	}
+
+	// 5. a page token that is not one
+	if _, err := audit.ListAuditEvents(admin, &auditpb.ListAuditEventsRequest{PageToken: "page 2"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a bad page token to be rejected, got %v", err)
+	}
+}
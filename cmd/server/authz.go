@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// author - the author id for a new blog. With auth enabled it is always the
+// token subject, the author_id sent in the request is ignored.
+func (b *Server) author(ctx context.Context, requested string) string {
+
+	if id, ok := middleware.IdentityFromContext(ctx); ok && id.Subject != "" {
+		return id.Subject
+	}
+
+	return requested
+}
+
+// authorize - fetches the blog identified by oid and ensures the caller owns it
+// or holds the admin claim.
+func (b *Server) authorize(ctx context.Context, oid primitive.ObjectID) (*blogItem, error) {
+
+	st, err := b.blogStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := st.FindBlog(ctx, oid)
+
+	if err != nil {
+		b.Logger.Errorf("could not fetch blog %v : %v", oid.Hex(), err)
+		return nil, storeError(err, resourceBlog, oid.Hex())
+	}
+
+	if !b.Config.Auth.Enabled {
+		return data, nil
+	}
+
+	id, ok := middleware.IdentityFromContext(ctx)
+
+	// a certificate alone owns no blogs
+	if !ok || id.Subject == "" {
+		return nil, status.Errorf(codes.Unauthenticated, "authentication required")
+	}
+
+	if !id.Admin && id.Subject != data.AuthorID {
+		b.Logger.Warnf("%v denied access to blog %v owned by %v", id.Subject, oid.Hex(), data.AuthorID)
+		return nil, status.Errorf(codes.PermissionDenied, "blog %v is not owned by %v", oid.Hex(), id.Subject)
+	}
+
+	return data, nil
+}
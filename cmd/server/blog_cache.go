@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"grpcourse/data/cache"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// listKey - the single entry holding ListBlog, it has no parameters
+const listKey = "blogs:list"
+
+func blogKey(oid primitive.ObjectID) string {
+	return "blog:" + oid.Hex()
+}
+
+// blogList - bson needs a document at the top level
+type blogList struct {
+	Blogs []blogItem `bson:"blogs"`
+}
+
+// UseCache - serves ReadBlog and ListBlog through c, nil turns caching off.
+// Concurrent reads of the same blog share one query either way.
+func (b *Server) UseCache(c cache.Cache, blogTTL, listTTL time.Duration) {
+
+	b.blogs = cache.NewLoader("blog", c, blogTTL)
+	b.lists = cache.NewLoader("list", c, listTTL)
+}
+
+// findBlog - one blog by id, through the cache
+func (b *Server) findBlog(ctx context.Context, oid primitive.ObjectID) (*blogItem, error) {
+
+	st, err := b.blogStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := b.blogs.Load(ctx, cacheKey(ctx, blogKey(oid)), func(ctx context.Context) ([]byte, error) {
+
+		data, err := st.FindBlog(ctx, oid)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return bson.Marshal(data)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := new(blogItem)
+
+	return data, bson.Unmarshal(raw, data)
+}
+
+// findBlogs - every blog, through the cache
+func (b *Server) findBlogs(ctx context.Context) ([]blogItem, error) {
+
+	st, err := b.blogStore(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := b.lists.Load(ctx, cacheKey(ctx, listKey), func(ctx context.Context) ([]byte, error) {
+
+		blogs, err := st.ListBlogs(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return bson.Marshal(blogList{Blogs: blogs})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var list blogList
+
+	return list.Blogs, bson.Unmarshal(raw, &list)
+}
+
+// invalidate - drops the cached copies of a changed blog and the list.
+// A failure leaves stale entries until their TTL runs out.
+func (b *Server) invalidate(ctx context.Context, oid *primitive.ObjectID) {
+
+	if oid != nil {
+
+		if err := b.blogs.Invalidate(ctx, cacheKey(ctx, blogKey(*oid))); err != nil {
+			b.Logger.Errorf("cannot invalidate cached blog %v : %v", oid.Hex(), err)
+		}
+	}
+
+	if err := b.lists.Invalidate(ctx, cacheKey(ctx, listKey)); err != nil {
+		b.Logger.Errorf("cannot invalidate cached blog list : %v", err)
+	}
+}
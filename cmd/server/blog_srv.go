@@ -3,43 +3,68 @@ package server
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"grpcourse/cmd/metrics"
+	"grpcourse/cmd/tracing"
+	"grpcourse/data/media"
 	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/store"
 	"io"
-	"os"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/label"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-type blogItem struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty"`
-	CoverImage string             `bson:"image"`
-	AuthorID   string             `bson:"author_id"`
-	Title      string             `bson:"title"`
-	Body       string             `bson:"body"`
-}
+// blogItem - a blog as stored, see store.Blog
+type blogItem = store.Blog
 
 const maxImageSize = 1 << 20
 
+// cleanupTimeout - deleting images replaced or left behind by a blog change
+const cleanupTimeout = 30 * time.Second
+
+// chunkSize - image bytes per DownloadImage message
+const chunkSize = 64 << 10
+
 // CreateBlog -  server handler for creating a new blog
-func (b *Server) CreateBlog(stream blogpb.BlogService_CreateBlogServer) error {
+func (b *Server) CreateBlog(stream blogpb.BlogService_CreateBlogServer) (err error) {
 
 	b.Logger.Infof("CreateBlog endpoint invoked")
 
+	ev := b.startAudit(stream.Context(), "CreateBlog")
+	defer func() { ev.finish(err) }()
+
+	st, err := b.blogStore(stream.Context())
+
+	if err != nil {
+		return err
+	}
+
 	// 1. receive the blog and image metadata first
 	req, err := stream.Recv()
+
+	if err != nil {
+		b.Logger.Errorf("cannot receive blog data : %v", err)
+		return status.Convert(err).Err()
+	}
+
 	blog := req.GetBlog()
 
-	// imageData := new(bytes.Buffer)
-	imageData := new(bytes.Buffer)
+	if blog == nil {
+		return invalidArgument("blog", "first message must carry the blog")
+	}
 
-	imgSize := 0
+	// 2. collect the image, it is decoded as a whole
+	var image bytes.Buffer
 
-	// 2. accept image chunks
 	for {
 
 		// a. stream from client
@@ -51,78 +76,117 @@ func (b *Server) CreateBlog(stream blogpb.BlogService_CreateBlogServer) error {
 		}
 
 		if err != nil {
-			return status.Errorf(codes.Unknown, fmt.Sprintf("cannot receive image data : %v", err))
+			b.Logger.Errorf("cannot receive image data : %v", err)
+			return status.Convert(err).Err()
 		}
 
 		chunk := ch.GetImage()
 
-		imgSize += len(chunk)
+		metrics.UploadBytes.Add(float64(len(chunk)))
 
-		if imgSize > maxImageSize {
-			imageData.Reset() // clean bytes buffer
+		if image.Len()+len(chunk) > maxImageSize {
+			metrics.UploadsRejected.Inc()
 			return status.Errorf(codes.InvalidArgument, fmt.Sprintf("file too large!"))
 		}
 
-		// b. write bytes to buffer
-		_, err = imageData.Write(chunk)
-
-		if err != nil {
-			return status.Errorf(codes.Internal, fmt.Sprintf("cannot write image data : %v", err))
-		}
+		// b. append to the image
+		image.Write(chunk)
 	}
 
-	// 3. save image to disks - TODO : migrate the logic to a func
-	imageName := "new_image.jpg"
+	// 3. store the image variants, a blog may come without an image
+	var (
+		cover    string
+		variants []store.Variant
+	)
 
-	file, err := os.Create("data/images/" + imageName)
+	if image.Len() > 0 {
 
-	if err != nil {
-		b.Logger.Errorf("cannot create image : %v", err)
-		return status.Errorf(codes.Internal, fmt.Sprintf("cannot create file : %v", err))
-	}
-
-	_, err = imageData.WriteTo(file)
-
-	if err != nil {
-		b.Logger.Errorf("cannot save image to disk : %v", err)
-		return status.Errorf(codes.Internal, fmt.Sprintf("cannot save image to disk : %v", err))
+		if cover, variants, err = b.storeImage(stream.Context(), image.Bytes()); err != nil {
+			return err
+		}
 	}
 
 	// 4. prepare document and save to collection
 	data := blogItem{
-		CoverImage: file.Name(),
-		AuthorID:   blog.GetAuthorId(),
+		CoverImage: cover,
+		Variants:   variants,
+		AuthorID:   b.author(stream.Context(), blog.GetAuthorId()),
 		Title:      blog.GetTitle(),
 		Body:       blog.GetBody(),
 	}
 
-	// pass context.TODO() or nil to default to context.Background()
-	res, err := b.DB.Collection("blog").InsertOne(nil, data)
+	// use the stream context so client deadlines are honoured by the driver
+	oid, err := st.InsertBlog(stream.Context(), &data)
 
 	if err != nil {
 		b.Logger.Errorf("couldn't create a new blog : %v", err)
-		return status.Errorf(codes.Internal, fmt.Sprintf("internal error : %v", err))
+		b.removeImages(imageKeys(&data)...)
+		return storeError(err, resourceBlog, blog.GetTitle())
 	}
 
 	b.Logger.Infof("New blog created successfully")
 
-	// Typecast insertedID to ObjectID
-	oid := res.InsertedID.(primitive.ObjectID)
+	data.ID = oid
+
+	ev.blog(oid)
+	ev.after(&data)
+
+	b.invalidate(stream.Context(), nil)
 
 	// 5. return response
 	response := &blogpb.CreateBlogResponse{
 		Blog: &blogpb.Blog{
-			Id:        oid.Hex(),
-			ImagePath: blog.GetImagePath(),
-			AuthorId:  blog.GetAuthorId(),
-			Title:     blog.GetTitle(),
-			Body:      blog.GetBody(),
+			Id:            oid.Hex(),
+			ImagePath:     data.CoverImage,
+			ImageVariants: imageVariants(&data),
+			AuthorId:      data.AuthorID,
+			Title:         blog.GetTitle(),
+			Body:          blog.GetBody(),
 		},
 	}
 
 	return stream.SendAndClose(response)
 }
 
+// saveImage - stores the image read from r under key
+func (b *Server) saveImage(ctx context.Context, key string, r io.Reader) error {
+
+	_, span := tracing.Start(ctx, "SaveImage", label.String("image.key", key))
+
+	n, err := b.Images.Put(ctx, key, r)
+
+	span.SetAttributes(label.Int64("image.size", n))
+
+	tracing.End(ctx, span, err)
+
+	if err != nil {
+		b.Logger.Errorf("cannot save image %v : %v", key, err)
+		return status.Errorf(codes.Internal, fmt.Sprintf("cannot save image : %v", err))
+	}
+
+	return nil
+}
+
+// removeImages - deletes images no blog refers to any more. Failures
+// only leave orphans behind, they are logged rather than returned.
+func (b *Server) removeImages(keys ...string) {
+
+	if len(keys) == 0 {
+		return
+	}
+
+	// the caller may have gone, the blog change is already made
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	for _, key := range keys {
+
+		if err := b.Images.Delete(ctx, key); err != nil {
+			b.Logger.Errorf("cannot delete image %v, it is orphaned : %v", key, err)
+		}
+	}
+}
+
 // ReadBlog - server handler for fetching a single blog from collection
 func (b *Server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*blogpb.ReadBlogResponse, error) {
 
@@ -133,39 +197,45 @@ func (b *Server) ReadBlog(ctx context.Context, req *blogpb.ReadBlogRequest) (*bl
 	oid, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
-		b.Logger.Errorf("could not parse blog id : %v")
-		return nil, status.Errorf(codes.InvalidArgument, fmt.Sprintf("cannot parse blog id : %v", err))
+		b.Logger.Errorf("could not parse blog id : %v", err)
+		return nil, invalidArgument("id", "must be a 24 character hex ObjectID")
 	}
 
-	data := new(blogItem)
+	data, err := b.findBlog(ctx, oid)
 
-	if err := b.DB.Collection("blog").FindOne(ctx, bson.D{primitive.E{Key: "_id", Value: oid}}).Decode(data); err != nil {
+	if err != nil {
 
-		if err == mongo.ErrNoDocuments {
+		if errors.Is(err, store.ErrNotFound) {
 			b.Logger.Errorf("document not found : %v", err)
-			return nil, status.Errorf(codes.NotFound, fmt.Sprintf("blog not found : %v", err))
+			return nil, notFound(resourceBlog, id)
 		}
 
 		b.Logger.Errorf("could not fetch blog : %v", err)
+		return nil, storeError(err, resourceBlog, id)
 	}
 
 	b.Logger.Printf("document fetched : %+v\n", data)
 
 	return &blogpb.ReadBlogResponse{
 		Blog: &blogpb.Blog{
-			Id:       data.ID.Hex(),
-			AuthorId: data.AuthorID,
-			Title:    data.Title,
-			Body:     data.Body,
+			Id:            data.ID.Hex(),
+			AuthorId:      data.AuthorID,
+			Title:         data.Title,
+			Body:          data.Body,
+			ImagePath:     data.CoverImage,
+			ImageVariants: imageVariants(data),
 		},
 	}, nil
 }
 
 // UpdateBlog -
-func (b *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (*blogpb.UpdateBlogResponse, error) {
+func (b *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest) (_ *blogpb.UpdateBlogResponse, err error) {
 
 	b.Logger.Infof("UpdateBlog func invoked")
 
+	ev := b.startAudit(ctx, "UpdateBlog")
+	defer func() { ev.finish(err) }()
+
 	// 1. Get blog from request
 	blog := req.GetBlog()
 
@@ -173,73 +243,134 @@ func (b *Server) UpdateBlog(ctx context.Context, req *blogpb.UpdateBlogRequest)
 
 	if err != nil {
 		b.Logger.Errorf("could not parse blog id : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot parse blog id : %v", err))
+		return nil, invalidArgument("blog.id", "must be a 24 character hex ObjectID")
 	}
 
-	// 1 a. Create image to file - ideally, image metadata could be passed from client
-	image := new(bytes.Buffer)
-	file, err := os.Create("data/images/updated_new_image.jpg")
+	ev.blog(oid)
+
+	// only the author (or an admin) may update a blog
+	current, err := b.authorize(ctx, oid)
 
 	if err != nil {
-		b.Logger.Errorf("cannot open image : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot open image : %v", err))
+		return nil, err
 	}
 
-	image.Write(req.GetImage())  // write bytes to buffer
-	_, err = image.WriteTo(file) // write to file
+	ev.before(current)
+
+	st, err := b.blogStore(ctx)
 
 	if err != nil {
-		b.Logger.Errorf("cannot save image : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot save image to disk : %v", err))
+		return nil, err
+	}
+
+	// 1 a. a new image replaces every variant, without one the current images stay
+	cover, variants := current.CoverImage, current.Variants
+
+	replaced := len(req.GetImage()) > 0
+
+	if replaced {
+
+		if cover, variants, err = b.storeImage(ctx, req.GetImage()); err != nil {
+			return nil, err
+		}
 	}
 
 	// 2b. alternatively
 	datab := &blogItem{
-		AuthorID:   blog.GetAuthorId(),
+		ID:         oid,
+		AuthorID:   current.AuthorID, // ownership never changes on update
 		Title:      blog.GetTitle(),
 		Body:       blog.GetBody(),
-		CoverImage: file.Name(),
+		CoverImage: cover,
+		Variants:   variants,
 	}
 
-	_, err = b.DB.Collection("blog").ReplaceOne(ctx, bson.D{primitive.E{Key: "_id", Value: oid}}, datab)
+	if err := st.ReplaceBlog(ctx, datab); err != nil {
+
+		if replaced {
+			b.removeImages(imageKeys(datab)...)
+		}
+
+		if errors.Is(err, store.ErrNotFound) {
+			b.Logger.Errorf("cannot update missing blog : %v", blog.GetId())
+			return nil, notFound(resourceBlog, blog.GetId())
+		}
 
-	if err != nil {
 		b.Logger.Errorf("cannot update record : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot update blog : %v", err))
+		return nil, storeError(err, resourceBlog, blog.GetId())
+	}
+
+	ev.after(datab)
+
+	b.invalidate(ctx, &oid)
+
+	// the blog points at the new images now
+	if replaced {
+		b.removeImages(imageKeys(current)...)
 	}
 
 	return &blogpb.UpdateBlogResponse{
 		Blog: &blogpb.Blog{
-			Id:        oid.Hex(),
-			AuthorId:  datab.AuthorID,
-			Title:     datab.Title,
-			Body:      datab.Body,
-			ImagePath: datab.CoverImage,
+			Id:            oid.Hex(),
+			AuthorId:      datab.AuthorID,
+			Title:         datab.Title,
+			Body:          datab.Body,
+			ImagePath:     datab.CoverImage,
+			ImageVariants: imageVariants(datab),
 		},
 	}, nil
 }
 
 // DeleteBlog - delete blog from collection
-func (b *Server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (*blogpb.DeleteBlogResponse, error) {
+func (b *Server) DeleteBlog(ctx context.Context, req *blogpb.DeleteBlogRequest) (_ *blogpb.DeleteBlogResponse, err error) {
 
 	b.Logger.Infof("DeleteBlog func invoked")
 
+	ev := b.startAudit(ctx, "DeleteBlog")
+	defer func() { ev.finish(err) }()
+
 	id := req.GetId()
 
 	oid, err := primitive.ObjectIDFromHex(id)
 
 	if err != nil {
 		b.Logger.Errorf("cannot parse id : %v", err)
-		return nil, status.Errorf(codes.InvalidArgument, fmt.Sprintf("couldn't parse id : %v", err))
+		return nil, invalidArgument("id", "must be a 24 character hex ObjectID")
+	}
+
+	ev.blog(oid)
+
+	// only the author (or an admin) may delete a blog
+	current, err := b.authorize(ctx, oid)
+
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = b.DB.Collection("blog").DeleteOne(ctx, bson.D{primitive.E{Key: "_id", Value: oid}})
+	ev.before(current)
+
+	st, err := b.blogStore(ctx)
 
 	if err != nil {
+		return nil, err
+	}
+
+	if err := st.DeleteBlog(ctx, oid); err != nil {
+
+		if errors.Is(err, store.ErrNotFound) {
+			b.Logger.Errorf("cannot delete missing blog : %v", id)
+			return nil, notFound(resourceBlog, id)
+		}
+
 		b.Logger.Errorf("cannot delete document id %v : %v", id, err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot delete document : %v", err))
+		return nil, storeError(err, resourceBlog, id)
 	}
 
+	b.invalidate(ctx, &oid)
+
+	// part of the delete - the images go with the blog
+	b.removeImages(imageKeys(current)...)
+
 	return &blogpb.DeleteBlogResponse{Id: id}, nil
 }
 
@@ -249,23 +380,16 @@ func (b *Server) ListBlog(ctx context.Context, req *blogpb.ListBlogRequest) (*bl
 	b.Logger.Infof("ListBlog func invoked")
 
 	// fetch all documents from blogs collection
-	res, err := b.DB.Collection("blog").Find(ctx, bson.D{{}})
-
-	if err != nil {
-		b.Logger.Errorf("could not fetch blogs : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("cannot fetch blogs : %v", err))
-	}
-
 	// 1. Option A :
 	// -  unmarshall documents to a slice of blogItem, prepare response and send it.
 	// 2. Option B :
 	// -  Using Cursor.Next(), we could do a server stream of the documents to the client
 	// - Either is okay... to the best of my knowledge
-	var blogs []blogItem
+	blogs, err := b.findBlogs(ctx)
 
-	if err := res.All(ctx, &blogs); err != nil {
-		b.Logger.Errorf("cannot unmarshall blogs : %v", err)
-		return nil, status.Errorf(codes.Internal, fmt.Sprintf("couldn't unmarshal blogs : %v", err))
+	if err != nil {
+		b.Logger.Errorf("could not fetch blogs : %v", err)
+		return nil, storeError(err, resourceBlog, "")
 	}
 
 	// prepare response and return
@@ -273,11 +397,12 @@ func (b *Server) ListBlog(ctx context.Context, req *blogpb.ListBlogRequest) (*bl
 
 	for _, b := range blogs {
 		result = append(result, &blogpb.Blog{
-			Id:        b.ID.Hex(),
-			AuthorId:  b.AuthorID,
-			Title:     b.Title,
-			Body:      b.Body,
-			ImagePath: b.CoverImage,
+			Id:            b.ID.Hex(),
+			AuthorId:      b.AuthorID,
+			Title:         b.Title,
+			Body:          b.Body,
+			ImagePath:     b.CoverImage,
+			ImageVariants: imageVariants(&b),
 		})
 	}
 
@@ -285,3 +410,96 @@ func (b *Server) ListBlog(ctx context.Context, req *blogpb.ListBlogRequest) (*bl
 		Blogs: result,
 	}, nil
 }
+
+// DownloadImage - streams the cover image of a blog, or one of its variants, in chunks
+func (b *Server) DownloadImage(req *blogpb.DownloadImageRequest, stream blogpb.BlogService_DownloadImageServer) error {
+
+	b.Logger.Infof("DownloadImage func invoked")
+
+	id := req.GetId()
+
+	oid, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		return invalidArgument("id", "must be a 24 character hex ObjectID")
+	}
+
+	st, err := b.blogStore(stream.Context())
+
+	if err != nil {
+		return err
+	}
+
+	// 1. find the image key
+	data, err := st.FindBlog(stream.Context(), oid)
+
+	if err != nil {
+		b.Logger.Errorf("could not fetch blog : %v", err)
+		return storeError(err, resourceBlog, id)
+	}
+
+	key := variantKey(data, req.GetVariant())
+
+	// keys are made by this server, never serve one outside the tenant's prefix
+	if key == "" || !strings.HasPrefix(key, b.imagePrefix(stream.Context())) {
+		return notFound("image", id)
+	}
+
+	file, err := b.Images.Open(stream.Context(), key)
+
+	if err != nil {
+
+		if errors.Is(err, media.ErrNotFound) {
+			return notFound("image", id)
+		}
+
+		b.Logger.Errorf("cannot open image : %v", err)
+		return status.Errorf(codes.Internal, fmt.Sprintf("cannot open image : %v", err))
+	}
+
+	defer file.Close()
+
+	// 2. send the image in chunks, the content type goes with the first one
+	buf := make([]byte, chunkSize)
+
+	first := true
+
+	for {
+
+		n, err := file.Read(buf)
+
+		if n > 0 {
+
+			res := &blogpb.DownloadImageResponse{Chunk: buf[:n]}
+
+			if first {
+				res.ContentType = contentType(key, buf[:n])
+				first = false
+			}
+
+			if err := stream.Send(res); err != nil {
+				b.Logger.Errorf("cannot send image chunk : %v", err)
+				return status.Convert(err).Err()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			b.Logger.Errorf("cannot read image : %v", err)
+			return status.Errorf(codes.Internal, fmt.Sprintf("cannot read image : %v", err))
+		}
+	}
+}
+
+// contentType - image media type from the file extension, sniffed when unknown
+func contentType(path string, head []byte) string {
+
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		return t
+	}
+
+	return http.DetectContentType(head)
+}
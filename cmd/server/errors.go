@@ -0,0 +1,171 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"grpcourse/data/store"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resourceBlog - resource type reported in ResourceInfo details
+const resourceBlog = "blog"
+
+// mongo server error codes we translate explicitly
+// see https://github.com/mongodb/mongo/blob/master/src/mongo/base/error_codes.yml
+const (
+	errCodeDuplicateKey       = 11000
+	errCodeDuplicateKeyLegacy = 11001
+	errCodeDuplicateKeyUpdate = 12582
+	errCodeMaxTimeMSExpired   = 50
+	errCodeUnauthorized       = 13
+	errCodeDocumentValidation = 121
+)
+
+// storeError - translates an error returned by the store or the mongo driver into a gRPC status.
+// resource and name identify the document the operation was acting on, they are
+// attached to the status as ResourceInfo so clients can act on them.
+func storeError(err error, resource, name string) error {
+
+	if err == nil {
+		return nil
+	}
+
+	// already translated further down the stack
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code, msg := classify(err)
+
+	st := status.New(code, fmt.Sprintf("%s : %v", msg, err))
+
+	return withDetails(st, &errdetails.ResourceInfo{
+		ResourceType: resource,
+		ResourceName: name,
+		Description:  msg,
+	})
+}
+
+// classify - maps a driver error to a status code and a short description
+func classify(err error) (codes.Code, string) {
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments), errors.Is(err, store.ErrNotFound):
+		return codes.NotFound, "document not found"
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded, "deadline exceeded"
+
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled, "request cancelled"
+
+	case errors.Is(err, mongo.ErrClientDisconnected):
+		return codes.Unavailable, "database unavailable"
+
+	case errors.Is(err, mongo.ErrNilDocument), errors.Is(err, mongo.ErrEmptySlice):
+		return codes.InvalidArgument, "invalid document"
+	}
+
+	// server selection errors are not typed by the driver
+	if strings.Contains(err.Error(), "server selection error") {
+		return codes.Unavailable, "database unavailable"
+	}
+
+	for _, c := range serverCodes(err) {
+
+		switch c {
+		case errCodeDuplicateKey, errCodeDuplicateKeyLegacy, errCodeDuplicateKeyUpdate:
+			return codes.AlreadyExists, "document already exists"
+
+		case errCodeMaxTimeMSExpired:
+			return codes.DeadlineExceeded, "deadline exceeded"
+
+		case errCodeUnauthorized:
+			return codes.PermissionDenied, "operation not permitted"
+
+		case errCodeDocumentValidation:
+			return codes.FailedPrecondition, "document failed validation"
+		}
+	}
+
+	return codes.Internal, "internal error"
+}
+
+// serverCodes - collects the server error codes carried by a driver error
+func serverCodes(err error) []int {
+
+	var (
+		cmdErr   mongo.CommandError
+		writeErr mongo.WriteException
+		bulkErr  mongo.BulkWriteException
+		result   []int
+	)
+
+	if errors.As(err, &cmdErr) {
+		result = append(result, int(cmdErr.Code))
+	}
+
+	if errors.As(err, &writeErr) {
+
+		for _, we := range writeErr.WriteErrors {
+			result = append(result, we.Code)
+		}
+
+		if writeErr.WriteConcernError != nil {
+			result = append(result, writeErr.WriteConcernError.Code)
+		}
+	}
+
+	if errors.As(err, &bulkErr) {
+
+		for _, we := range bulkErr.WriteErrors {
+			result = append(result, we.Code)
+		}
+	}
+
+	return result
+}
+
+// invalidArgument - InvalidArgument status carrying a BadRequest field violation
+func invalidArgument(field, description string) error {
+
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("invalid %s : %s", field, description))
+
+	return withDetails(st, &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: description},
+		},
+	})
+}
+
+// notFound - NotFound status carrying ResourceInfo
+func notFound(resource, name string) error {
+
+	st := status.New(codes.NotFound, fmt.Sprintf("%s not found : %s", resource, name))
+
+	return withDetails(st, &errdetails.ResourceInfo{
+		ResourceType: resource,
+		ResourceName: name,
+		Description:  resource + " does not exist",
+	})
+}
+
+// withDetails - attaches details to a status, falling back to the bare status
+// if the details cannot be marshalled.
+func withDetails(st *status.Status, details ...proto.Message) error {
+
+	ds, err := st.WithDetails(details...)
+
+	if err != nil {
+		return st.Err()
+	}
+
+	return ds.Err()
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStoreError(t *testing.T) {
+
+	cases := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{"no documents", mongo.ErrNoDocuments, codes.NotFound},
+		{"deadline", fmt.Errorf("wrapped : %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"cancelled", context.Canceled, codes.Canceled},
+		{"disconnected", mongo.ErrClientDisconnected, codes.Unavailable},
+		{"server selection", errors.New("server selection error: server selection timeout"), codes.Unavailable},
+		{"duplicate key", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000"}}}, codes.AlreadyExists},
+		{"max time", mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}, codes.DeadlineExceeded},
+		{"unknown", errors.New("boom"), codes.Internal},
+	}
+
+	for _, c := range cases {
+
+		err := storeError(c.err, resourceBlog, "5f2011c0f7bc9e1a387c2a1e")
+
+		st := status.Convert(err)
+
+		if st.Code() != c.code {
+			t.Errorf("%s : expected %v got %v", c.name, c.code, st.Code())
+		}
+
+		if len(st.Details()) != 1 {
+			t.Fatalf("%s : expected resource info details, got %v", c.name, st.Details())
+		}
+
+		if info, ok := st.Details()[0].(*errdetails.ResourceInfo); !ok || info.GetResourceType() != resourceBlog {
+			t.Errorf("%s : unexpected details %v", c.name, st.Details())
+		}
+	}
+}
+
+func TestInvalidArgument(t *testing.T) {
+
+	st := status.Convert(invalidArgument("id", "must be a 24 character hex ObjectID"))
+
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument got %v", st.Code())
+	}
+
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+
+	if !ok || br.GetFieldViolations()[0].GetField() != "id" {
+		t.Errorf("unexpected details %v", st.Details())
+	}
+}
@@ -3,15 +3,17 @@ package server
 import (
 	"context"
 	"fmt"
-	"grpcourse/data/db"
+	"grpcourse/config"
+	"grpcourse/data/cache"
+	"grpcourse/data/media"
 	"grpcourse/data/protos/greet"
+	"grpcourse/data/store"
 	"io"
 	"math"
 	"strconv"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"go.mongodb.org/mongo-driver/mongo"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -19,16 +21,33 @@ import (
 // Server -
 type Server struct {
 	Logger *logrus.Logger
-	DB     *mongo.Database
+	Store  store.Store
+	Images media.Store
+	Config *config.Config
+
+	// TenantStore - set when tenancy is on, returns the store of a tenant.
+	// Blogs then live in per-tenant databases and Store only answers health
+	// checks.
+	TenantStore func(id string) store.Store
+
+	// read-through access to blogs and the blog list (see UseCache)
+	blogs *cache.Loader
+	lists *cache.Loader
 }
 
-// NewServer - returns Server
-func NewServer() *Server {
+// NewServer - returns Server keeping blogs in st
+func NewServer(cfg *config.Config, st store.Store) *Server {
 
-	return &Server{
+	s := &Server{
 		Logger: logrus.New(),
-		DB:     db.GetDB(),
+		Store:  st,
+		Images: media.NewDisk(cfg.Images.Dir),
+		Config: cfg,
 	}
+
+	s.UseCache(nil, 0, 0)
+
+	return s
 }
 
 // Greet -
@@ -38,8 +57,8 @@ func (g *Server) Greet(ctx context.Context, req *greet.GreetRequest) (*greet.Gre
 
 	var fName, lName string
 
-	fName = req.Greeting.GetFirstName()
-	lName = req.Greeting.GetSecondName()
+	fName = req.GetGreeting().GetFirstName()
+	lName = req.GetGreeting().GetSecondName()
 
 	// log
 	g.Logger.Infof("Greetings to FirstName : %v LastName : %v", fName, lName)
@@ -77,7 +96,7 @@ func (g *Server) GreetAlot(req *greet.GreetRequest, stream greet.GreetService_Gr
 
 	g.Logger.Infof("GreetAlot func invoked")
 
-	name := req.Greeting.GetFirstName() + " " + req.Greeting.GetSecondName()
+	name := req.GetGreeting().GetFirstName() + " " + req.GetGreeting().GetSecondName()
 
 	for i := 0; i <= 10; i++ {
 
@@ -111,12 +130,23 @@ func (g *Server) PrimeNumberDecomposition(req *greet.PMRequest, stream greet.Gre
 
 	for N > 1 {
 
+		// a client that went away stops the work, not only the next send
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		// no factor up to the square root, what is left is prime
+		if n*n > N {
+			n = N
+		}
+
 		if N%n == 0 {
 
 			res := &greet.PMResponse{PrimeFactor: n}
 
 			if err := stream.Send(res); err != nil {
-				g.Logger.Fatalf("cannot send data to stream : %v", err)
+				g.Logger.Errorf("cannot send data to stream : %v", err)
+				return status.Convert(err).Err()
 			}
 
 			N = N / n
@@ -151,11 +181,11 @@ func (g *Server) LongGreet(stream greet.GreetService_LongGreetServer) error {
 		}
 
 		if err != nil {
-			g.Logger.Fatalf("cannot read from stream : %v", err)
+			g.Logger.Errorf("cannot read from stream : %v", err)
 			return err
 		}
 
-		greeting := "Hallo " + req.Greeting.GetSecondName()
+		greeting := "Hallo " + req.GetGreeting().GetSecondName()
 
 		fmt.Println("greeting : ", greeting)
 
@@ -176,18 +206,23 @@ func (g *Server) ComputeAverage(stream greet.GreetService_ComputeAverageServer)
 
 		req, err := stream.Recv()
 
-		sum += req.GetNumber()
-
 		if err == io.EOF {
+
+			if counter == 0 {
+				return status.Errorf(codes.InvalidArgument, "no numbers received")
+			}
+
 			g.Logger.Printf("sum : %v counter : %v\n", sum, counter)
 			res := &greet.AverageResponse{Average: float64(sum / counter)}
 			return stream.SendAndClose(res)
 		}
 
 		if err != nil {
-			g.Logger.Fatalf("couldn't get values from stream : %v", err)
+			g.Logger.Errorf("couldn't get values from stream : %v", err)
+			return err
 		}
 
+		sum += req.GetNumber()
 		counter++
 	}
 
@@ -208,15 +243,15 @@ func (g *Server) GreetEveryone(stream greet.GreetService_GreetEveryoneServer) er
 		}
 
 		if err != nil {
-			return fmt.Errorf("could not read greetings from client stream : %v", err)
+			g.Logger.Errorf("could not read greetings from client stream : %v", err)
+			return err
 		}
 
-		res := &greet.GreetResponse{Response: "Hi " + req.Greeting.FirstName}
+		res := &greet.GreetResponse{Response: "Hi " + req.GetGreeting().GetFirstName()}
 
 		if err := stream.Send(res); err != nil {
-			g.Logger.Fatalf("cannot send greet everyone res : %v", err)
-
-			return err
+			g.Logger.Errorf("cannot send greet everyone res : %v", err)
+			return status.Convert(err).Err()
 		}
 	}
 
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"grpcourse/config"
+	"grpcourse/data/protos/greet"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestPrimeNumberDecomposition(t *testing.T) {
+
+	svr := NewServer(config.Default(), nil)
+	svr.Logger.SetLevel(logrus.PanicLevel)
+
+	client := greet.NewGreetServiceClient(serve(t, svr))
+
+	// 2 x 999999937, the second factor used to take a billion divisions
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.PrimeNumberDecomposition(ctx, &greet.PMRequest{Number: 1999999874})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var factors []int64
+
+	for {
+
+		res, err := stream.Recv()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			t.Fatalf("cannot receive : %v", err)
+		}
+
+		factors = append(factors, res.GetPrimeFactor())
+	}
+
+	if len(factors) != 2 || factors[0] != 2 || factors[1] != 999999937 {
+		t.Errorf("unexpected factors %v", factors)
+	}
+}
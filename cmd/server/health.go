@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// service names reported through grpc.health.v1 - they match the proto service names
+const (
+	GreetServiceName = "GreetService"
+	BlogServiceName  = "BlogService"
+	AuditServiceName = "AuditService"
+)
+
+// WatchHealth - keeps the health statuses up to date until ctx is done.
+// BlogService and AuditService follow the store ping, GreetService has no dependencies.
+func (b *Server) WatchHealth(ctx context.Context, hs *health.Server, interval, timeout time.Duration) {
+
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus(GreetServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	// not serving until the store answers
+	hs.SetServingStatus(BlogServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+	hs.SetServingStatus(AuditServiceName, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	current := healthpb.HealthCheckResponse_NOT_SERVING
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+
+		next := b.checkDB(ctx, timeout)
+
+		if next != current {
+			b.Logger.Infof("%v health changed from %v to %v", BlogServiceName, current, next)
+			hs.SetServingStatus(BlogServiceName, next)
+			hs.SetServingStatus(AuditServiceName, next)
+			current = next
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDB - pings the store within timeout
+func (b *Server) checkDB(ctx context.Context, timeout time.Duration) healthpb.HealthCheckResponse_ServingStatus {
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := b.Store.Ping(ctx); err != nil {
+		b.Logger.Warnf("store ping failed : %v", err)
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
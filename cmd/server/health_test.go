@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"grpcourse/config"
+	"grpcourse/data/store"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// flakyStore - a store whose ping fails while down is set
+type flakyStore struct {
+	store.Store
+	down int32
+}
+
+func (s *flakyStore) Ping(ctx context.Context) error {
+
+	if atomic.LoadInt32(&s.down) == 1 {
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+func TestWatchHealth(t *testing.T) {
+
+	st := &flakyStore{}
+
+	svr := NewServer(config.Default(), st)
+	svr.Logger.SetLevel(logrus.PanicLevel)
+
+	hs := health.NewServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go svr.WatchHealth(ctx, hs, 10*time.Millisecond, time.Second)
+
+	// waitFor - polls until every blog service reports want
+	waitFor := func(want healthpb.HealthCheckResponse_ServingStatus) {
+
+		deadline := time.Now().Add(2 * time.Second)
+
+		for _, service := range []string{BlogServiceName, AuditServiceName} {
+
+			for {
+
+				res, err := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+
+				if err == nil && res.Status == want {
+					break
+				}
+
+				if time.Now().After(deadline) {
+					t.Fatalf("%v : expected %v, got %v %v", service, want, res.GetStatus(), err)
+				}
+
+				time.Sleep(5 * time.Millisecond)
+			}
+		}
+	}
+
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+
+	// a failing ping takes the store backed services out
+	atomic.StoreInt32(&st.down, 1)
+	waitFor(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	res, _ := hs.Check(ctx, &healthpb.HealthCheckRequest{Service: GreetServiceName})
+
+	if res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("%v should not depend on the store, got %v", GreetServiceName, res.GetStatus())
+	}
+
+	// and back in once it answers again
+	atomic.StoreInt32(&st.down, 0)
+	waitFor(healthpb.HealthCheckResponse_SERVING)
+}
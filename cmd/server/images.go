@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"grpcourse/cmd/metrics"
+	"grpcourse/cmd/tracing"
+	"grpcourse/data/imaging"
+	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/store"
+	"mime"
+	"path"
+
+	"go.opentelemetry.io/otel/label"
+)
+
+// storeImage - checks that data is an image and stores every variant of
+// it, upright and stripped of metadata. Returns the key of the original,
+// which becomes the cover image. Nothing is left behind on errors.
+func (b *Server) storeImage(ctx context.Context, data []byte) (string, []store.Variant, error) {
+
+	// 1. decode and re-encode
+	_, span := tracing.Start(ctx, "ProcessImage", label.Int("image.size", len(data)))
+
+	images, err := imaging.Process(data, imaging.Variants)
+
+	tracing.End(ctx, span, err)
+
+	if err != nil {
+		metrics.UploadsRejected.Inc()
+		b.Logger.Errorf("image upload rejected : %v", err)
+		return "", nil, invalidArgument("image", err.Error())
+	}
+
+	// 2. the variants share one base key eg. team-a/5f2011c0...-thumbnail.jpg
+	base := b.imageKey(ctx)
+
+	var (
+		cover    string
+		variants []store.Variant
+	)
+
+	for _, img := range images {
+
+		key := base + "-" + img.Name + img.Ext
+
+		if err := b.saveImage(ctx, key, bytes.NewReader(img.Data)); err != nil {
+			b.removeImages(imageKeys(&blogItem{Variants: variants})...)
+			return "", nil, err
+		}
+
+		variants = append(variants, store.Variant{
+			Name:        img.Name,
+			Key:         key,
+			ContentType: img.ContentType,
+			Width:       img.Width,
+			Height:      img.Height,
+		})
+
+		if img.Name == imaging.Original {
+			cover = key
+		}
+	}
+
+	return cover, variants, nil
+}
+
+// imageKeys - every stored image of a blog
+func imageKeys(item *blogItem) []string {
+
+	var keys []string
+
+	if item.CoverImage != "" {
+		keys = append(keys, item.CoverImage)
+	}
+
+	for _, v := range item.Variants {
+
+		if v.Key != item.CoverImage {
+			keys = append(keys, v.Key)
+		}
+	}
+
+	return keys
+}
+
+// variantKey - the image key of the named variant, "" when the blog has none
+func variantKey(item *blogItem, name string) string {
+
+	if name == "" || name == imaging.Original {
+		return item.CoverImage
+	}
+
+	for _, v := range item.Variants {
+
+		if v.Name == name {
+			return v.Key
+		}
+	}
+
+	return ""
+}
+
+// imageVariants - the variants of a blog as returned to clients. Blogs from
+// before image processing only have their cover image, as the original.
+func imageVariants(item *blogItem) []*blogpb.ImageVariant {
+
+	variants := item.Variants
+
+	if len(variants) == 0 && item.CoverImage != "" {
+		variants = []store.Variant{{
+			Name:        imaging.Original,
+			Key:         item.CoverImage,
+			ContentType: mime.TypeByExtension(path.Ext(item.CoverImage)),
+		}}
+	}
+
+	var result []*blogpb.ImageVariant
+
+	for _, v := range variants {
+		result = append(result, &blogpb.ImageVariant{
+			Name:        v.Name,
+			Key:         v.Key,
+			Url:         fmt.Sprintf("/v1/blogs/%s/image?variant=%s", item.ID.Hex(), v.Name),
+			ContentType: v.ContentType,
+			Width:       int32(v.Width),
+			Height:      int32(v.Height),
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"grpcourse/cmd/middleware"
+	"grpcourse/data/imaging"
+	auditpb "grpcourse/data/protos/audit"
+	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/protos/greet"
+	"math"
+)
+
+// validation limits
+const (
+	maxNameLen   = 64
+	maxTitleLen  = 120
+	maxBodyLen   = 10000
+	maxAuthorLen = 64
+
+	// trial division in PrimeNumberDecomposition is linear in the number
+	maxPrimeInput = 1000000000
+)
+
+// validation rules for every request message - enforced by the
+// validation interceptor before the handlers run
+func init() {
+
+	// 1. greet service
+	middleware.Register(&greet.GreetRequest{}, middleware.Rules{
+		"greeting":            {middleware.Required()},
+		"greeting.firstName":  {middleware.Required(), middleware.Length(1, maxNameLen)},
+		"greeting.secondName": {middleware.Length(0, maxNameLen)},
+	})
+
+	middleware.Register(&greet.SumRequest{}, middleware.Rules{
+		"a": {middleware.Range(math.MinInt32, math.MaxInt32)},
+		"b": {middleware.Range(math.MinInt32, math.MaxInt32)},
+	})
+
+	middleware.Register(&greet.PMRequest{}, middleware.Rules{
+		"number": {middleware.Range(2, maxPrimeInput)},
+	})
+
+	middleware.Register(&greet.NumberRequest{}, middleware.Rules{
+		"number": {middleware.Range(math.MinInt32, math.MaxInt32)},
+	})
+
+	middleware.Register(&greet.SquareRootRequest{}, middleware.Rules{
+		"number": {middleware.Range(0, math.MaxInt32)},
+	})
+
+	// 2. blog service
+	middleware.Register(&blogpb.CreateBlogRequest{}, middleware.Rules{
+		"blog.author_id": {middleware.Length(0, maxAuthorLen)},
+		"blog.title":     {middleware.Required(), middleware.Length(1, maxTitleLen)},
+		"blog.body":      {middleware.Required(), middleware.Length(1, maxBodyLen)},
+		"image":          {middleware.MaxBytes(maxImageSize)},
+	})
+
+	middleware.Register(&blogpb.ReadBlogRequest{}, middleware.Rules{
+		"id": {middleware.Required(), middleware.ObjectID()},
+	})
+
+	middleware.Register(&blogpb.UpdateBlogRequest{}, middleware.Rules{
+		"blog":           {middleware.Required()},
+		"blog.id":        {middleware.Required(), middleware.ObjectID()},
+		"blog.author_id": {middleware.Length(0, maxAuthorLen)},
+		"blog.title":     {middleware.Required(), middleware.Length(1, maxTitleLen)},
+		"blog.body":      {middleware.Required(), middleware.Length(1, maxBodyLen)},
+		"image":          {middleware.MaxBytes(maxImageSize)},
+	})
+
+	middleware.Register(&blogpb.DeleteBlogRequest{}, middleware.Rules{
+		"id": {middleware.Required(), middleware.ObjectID()},
+	})
+
+	var variants []string
+
+	for _, spec := range imaging.Variants {
+		variants = append(variants, spec.Name)
+	}
+
+	middleware.Register(&blogpb.DownloadImageRequest{}, middleware.Rules{
+		"id":      {middleware.Required(), middleware.ObjectID()},
+		"variant": {middleware.OneOf(variants...)},
+	})
+
+	// 3. audit service
+	middleware.Register(&auditpb.ListAuditEventsRequest{}, middleware.Rules{
+		"actor":     {middleware.Length(0, maxAuthorLen)},
+		"page_size": {middleware.Range(0, maxAuditPage)},
+	})
+}
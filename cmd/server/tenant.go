@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+	"grpcourse/data/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// blogStore - the store of the calling tenant, the shared one without tenancy.
+// Blogs of other tenants live in other databases, so an id from another
+// tenant is simply not found.
+func (b *Server) blogStore(ctx context.Context) (store.Store, error) {
+
+	if b.TenantStore == nil {
+		return b.Store, nil
+	}
+
+	id := middleware.TenantFromContext(ctx)
+
+	// the resolver runs on every method that is not exempt
+	if id == "" {
+		b.Logger.Errorf("blog call without a tenant, is the method in tenancy.exempt?")
+		return nil, status.Errorf(codes.FailedPrecondition, "no tenant")
+	}
+
+	return b.TenantStore(id), nil
+}
+
+// imagePrefix - starts the key of every image of the calling tenant
+func (b *Server) imagePrefix(ctx context.Context) string {
+
+	if id := middleware.TenantFromContext(ctx); b.TenantStore != nil && id != "" {
+		return TenantImagePrefix(id)
+	}
+
+	return ""
+}
+
+// TenantImagePrefix - image key prefix of tenant id, used when provisioning
+func TenantImagePrefix(id string) string {
+	return id + "/"
+}
+
+// imageKey - a new, unique base for the keys of an uploaded image
+func (b *Server) imageKey(ctx context.Context) string {
+	return b.imagePrefix(ctx) + primitive.NewObjectID().Hex()
+}
+
+// cacheKey - cache entries are kept apart per tenant as well
+func cacheKey(ctx context.Context, key string) string {
+
+	if id := middleware.TenantFromContext(ctx); id != "" {
+		return "tenant:" + id + ":" + key
+	}
+
+	return key
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"grpcourse/cmd/middleware"
+	"grpcourse/config"
+	auditpb "grpcourse/data/protos/audit"
+	blogpb "grpcourse/data/protos/blog"
+	"grpcourse/data/protos/greet"
+	"grpcourse/data/store"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve - runs svr with opts until the test ends, returns a connection to it
+func serve(t *testing.T, svr *Server, opts ...grpc.ServerOption) *grpc.ClientConn {
+
+	lis := bufconn.Listen(1 << 20)
+
+	gs := grpc.NewServer(opts...)
+	greet.RegisterGreetServiceServer(gs, svr)
+	blogpb.RegisterBlogServiceServer(gs, svr)
+	auditpb.RegisterAuditServiceServer(gs, svr)
+
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// tenantServer - a blog server with tenants red and blue, each on its own
+// bolt store, behind the auth and tenant interceptors
+func tenantServer(t *testing.T) (blogpb.BlogServiceClient, *middleware.Authenticator, map[string]store.Store) {
+
+	cfg := config.Default()
+	cfg.Auth.SigningKey = "test"
+	cfg.Tenancy.Enabled = true
+
+	stores := map[string]store.Store{}
+
+	for _, id := range []string{"red", "blue"} {
+
+		st, err := store.NewBolt(filepath.Join(t.TempDir(), id+".db"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() { st.Close(context.Background()) })
+
+		stores[id] = st
+	}
+
+	svr := NewServer(cfg, nil)
+	svr.Logger.SetLevel(logrus.PanicLevel)
+	svr.TenantStore = func(id string) store.Store { return stores[id] }
+
+	auth := middleware.NewAuthenticator(cfg.Auth)
+
+	tenants := middleware.NewTenantResolver(cfg.Tenancy, func(_ context.Context, id string) (bool, error) {
+		return stores[id] != nil, nil
+	})
+
+	conn := serve(t, svr, grpc.ChainUnaryInterceptor(auth.Unary(), tenants.Unary()))
+
+	return blogpb.NewBlogServiceClient(conn), auth, stores
+}
+
+func TestCrossTenantRead(t *testing.T) {
+
+	client, auth, stores := tenantServer(t)
+
+	oid, err := stores["red"].InsertBlog(context.Background(), &store.Blog{AuthorID: "1001", Title: "red only", Body: "secret"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := func(tenant string, admin bool) string {
+
+		s, err := auth.IssueFor("1001", tenant, admin, time.Minute)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return "Bearer " + s
+	}
+
+	cases := []struct {
+		name   string
+		token  string
+		header string
+		code   codes.Code
+	}{
+		{"own tenant", token("red", false), "", codes.OK},
+		{"other tenant", token("blue", false), "", codes.NotFound},
+		{"other tenant picks", token("blue", false), "red", codes.PermissionDenied},
+		{"unbound token picks", token("", false), "red", codes.PermissionDenied},
+		{"anonymous picks", "", "red", codes.PermissionDenied},
+		{"admin picks", token("", true), "red", codes.OK},
+	}
+
+	for _, c := range cases {
+
+		md := metadata.MD{}
+
+		if c.token != "" {
+			md.Set("authorization", c.token)
+		}
+
+		if c.header != "" {
+			md.Set("x-tenant-id", c.header)
+		}
+
+		ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+		res, err := client.ReadBlog(ctx, &blogpb.ReadBlogRequest{Id: oid.Hex()})
+
+		if status.Code(err) != c.code {
+			t.Errorf("%v : expected %v, got %v", c.name, c.code, err)
+			continue
+		}
+
+		if err == nil && res.GetBlog().GetTitle() != "red only" {
+			t.Errorf("%v : unexpected blog %v", c.name, res.GetBlog())
+		}
+	}
+}
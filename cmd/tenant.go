@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/cmd/server"
+	"grpcourse/data/db"
+	"grpcourse/data/migrate"
+	"grpcourse/data/tenant"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// tenantTimeout - every tenant command is a handful of mongo round trips
+const tenantTimeout = 30 * time.Second
+
+var tenantCmd = &cobra.Command{
+	Use:   "tenant",
+	Short: "Provision the teams sharing the server (tenancy.enabled)",
+	Long: `Every tenant gets the database <tenancy.database_prefix><id> and the
+image key prefix <id>/. Callers pick their tenant with a token
+issued with --tenant, or with the tenancy.header metadata.`,
+}
+
+var tenantCreateCmd = &cobra.Command{
+	Use:   "create <id>",
+	Short: "Provision a tenant",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx, cancel := context.WithTimeout(context.Background(), tenantTimeout)
+		defer cancel()
+
+		name, _ := cmd.Flags().GetString("name")
+
+		t, err := registry().Create(ctx, args[0], name)
+
+		if err != nil {
+			return fmt.Errorf("cannot create tenant %v : %v", args[0], err)
+		}
+
+		// new tenants start at the latest schema
+		d := registry().Database(t.ID)
+
+		if _, err := migrate.Up(ctx, d, migrate.Migrations, 0); err != nil {
+			return fmt.Errorf("cannot migrate %v : %v", d.Name(), err)
+		}
+
+		if err := reconcileIndexes(ctx, d, logrus.StandardLogger()); err != nil {
+			return err
+		}
+
+		fmt.Printf("tenant %v created, database %v%v\n", t.ID, cfg.Tenancy.DatabasePrefix, t.ID)
+
+		return nil
+	},
+}
+
+var tenantListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the provisioned tenants",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx, cancel := context.WithTimeout(context.Background(), tenantTimeout)
+		defer cancel()
+
+		list, err := registry().List(ctx)
+
+		if err != nil {
+			return fmt.Errorf("cannot list tenants : %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(w, "ID\tNAME\tCREATED")
+
+		for _, t := range list {
+			fmt.Fprintf(w, "%v\t%v\t%v\n", t.ID, t.Name, t.Created.Format(time.RFC3339))
+		}
+
+		return w.Flush()
+	},
+}
+
+var tenantDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Remove a tenant, --drop also deletes its blogs and images",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ctx, cancel := context.WithTimeout(context.Background(), tenantTimeout)
+		defer cancel()
+
+		drop, _ := cmd.Flags().GetBool("drop")
+
+		if err := registry().Delete(ctx, args[0], drop); err != nil {
+			return fmt.Errorf("cannot delete tenant %v : %v", args[0], err)
+		}
+
+		if drop {
+
+			images, err := openImages(cfg.Images)
+
+			if err != nil {
+				return err
+			}
+
+			if err := images.DeletePrefix(ctx, server.TenantImagePrefix(args[0])); err != nil {
+				return fmt.Errorf("cannot delete the images of %v : %v", args[0], err)
+			}
+		}
+
+		fmt.Printf("tenant %v deleted\n", args[0])
+
+		return nil
+	},
+}
+
+// registry - the tenants known to the configured mongo
+func registry() *tenant.Registry {
+	return tenant.NewRegistry(db.GetDB(), cfg.Tenancy.DatabasePrefix)
+}
+
+func init() {
+	tenantCreateCmd.Flags().String("name", "", "display name of the team")
+	tenantDeleteCmd.Flags().Bool("drop", false, "drop the tenant database and images as well")
+
+	tenantCmd.AddCommand(tenantCreateCmd, tenantListCmd, tenantDeleteCmd)
+
+	rootCmd.AddCommand(tenantCmd)
+}
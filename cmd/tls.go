@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"grpcourse/cmd/pki"
+	"grpcourse/config"
+	"io/ioutil"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/credentials"
+)
+
+// serverCredentials - TLS credentials for the gRPC server. The certificate is
+// served through a reloader so it can be replaced without a restart. Client
+// certificates signed by the configured CA are required when c.ClientCA is set (mutual TLS).
+func serverCredentials(c config.TLS, logger *logrus.Logger) (credentials.TransportCredentials, *pki.Reloader, error) {
+
+	reloader, err := pki.NewReloader(c.Cert, c.Key, logger)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load server certificate : %v", err)
+	}
+
+	conf, err := serverTLSConfig(c, reloader)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return credentials.NewTLS(conf), reloader, nil
+}
+
+// serverTLSConfig - TLS settings shared by every listener serving the gRPC
+// services, so HTTP front ends enforce the same client certificates
+func serverTLSConfig(c config.TLS, reloader *pki.Reloader) (*tls.Config, error) {
+
+	conf := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+
+	if c.ClientCA != "" {
+
+		pool, err := certPool(c.ClientCA)
+
+		if err != nil {
+			return nil, err
+		}
+
+		conf.ClientCAs = pool
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return conf, nil
+}
+
+// clientCredentials - TLS credentials for the client trusting ca. The client
+// certificate is presented to the server when cert and key are set.
+func clientCredentials(ca, cert, key string) (credentials.TransportCredentials, error) {
+
+	pool, err := certPool(ca)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cert != "" || key != "" {
+
+		pair, err := tls.LoadX509KeyPair(cert, key)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate : %v", err)
+		}
+
+		conf.Certificates = []tls.Certificate{pair}
+	}
+
+	return credentials.NewTLS(conf), nil
+}
+
+// certPool - reads PEM encoded CA certificates from path
+func certPool(path string) (*x509.CertPool, error) {
+
+	pem, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CA certificate : %v", err)
+	}
+
+	pool := x509.NewCertPool()
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %v", path)
+	}
+
+	return pool, nil
+}
+
+// listenerCredentials - TLS credentials skipping the handshake on connections
+// from plaintext listeners, so one gRPC server can serve a local socket in
+// the clear next to TLS over TCP
+type listenerCredentials struct {
+	credentials.TransportCredentials
+}
+
+// ServerHandshake -
+func (c listenerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+
+	if _, ok := conn.(plainConn); ok {
+		return conn, nil, nil
+	}
+
+	return c.TransportCredentials.ServerHandshake(conn)
+}
+
+// Clone -
+func (c listenerCredentials) Clone() credentials.TransportCredentials {
+	return listenerCredentials{c.TransportCredentials.Clone()}
+}
+
+// plainListener - marks accepted connections as plaintext for listenerCredentials
+type plainListener struct {
+	net.Listener
+}
+
+// Accept -
+func (l plainListener) Accept() (net.Conn, error) {
+
+	conn, err := l.Listener.Accept()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return plainConn{conn}, nil
+}
+
+type plainConn struct {
+	net.Conn
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"grpcourse/cmd/middleware"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tokenSubject string
+	tokenTenant  string
+	tokenAdmin   bool
+	tokenTTL     time.Duration
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Issue a signed bearer token for development",
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		token, err := middleware.NewAuthenticator(cfg.Auth).IssueFor(tokenSubject, tokenTenant, tokenAdmin, tokenTTL)
+
+		if err != nil {
+			return fmt.Errorf("cannot sign token : %v", err)
+		}
+
+		fmt.Println(token)
+
+		return nil
+	},
+}
+
+func init() {
+	tokenCmd.Flags().StringVar(&tokenSubject, "sub", "1001", "subject (author id) of the token")
+	tokenCmd.Flags().StringVar(&tokenTenant, "tenant", "", "bind the token to a tenant (tenancy.enabled)")
+	tokenCmd.Flags().BoolVar(&tokenAdmin, "admin", false, "grant the admin claim")
+	tokenCmd.Flags().DurationVar(&tokenTTL, "ttl", 24*time.Hour, "token lifetime")
+
+	rootCmd.AddCommand(tokenCmd)
+}
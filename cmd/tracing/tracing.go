@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"grpcourse/config"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/label"
+	"go.opentelemetry.io/otel/propagators"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// instrumentation name of the spans started by this repo
+const name = "grpcourse"
+
+// Exporters selectable in config.Tracing
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup - installs the global tracer provider and the W3C trace context
+// propagator. The returned func flushes pending spans, call it before exit.
+func Setup(cfg config.Tracing, service string) (func(context.Context) error, error) {
+
+	// propagate even when not exporting so a traced caller keeps its trace id downstream
+	global.SetTextMapPropagator(otel.NewCompositeTextMapPropagator(propagators.TraceContext{}, propagators.Baggage{}))
+
+	if cfg.Exporter == "" || cfg.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closer, err := newExporter(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bsp := sdktrace.NewBatchSpanProcessor(exporter)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithConfig(sdktrace.Config{
+			DefaultSampler: sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio)),
+			Resource:       resource.New(semconv.ServiceNameKey.String(service)),
+		}),
+		sdktrace.WithSpanProcessor(bsp),
+	)
+
+	global.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+
+		// unregistering shuts the processor down, flushing queued spans
+		provider.UnregisterSpanProcessor(bsp)
+
+		if err := exporter.Shutdown(ctx); err != nil {
+			return err
+		}
+
+		if closer != nil {
+			return closer.Close()
+		}
+
+		return nil
+	}, nil
+}
+
+// newExporter - span exporter named by cfg.Exporter, plus the file to close on shutdown
+func newExporter(cfg config.Tracing) (export.SpanExporter, io.Closer, error) {
+
+	switch cfg.Exporter {
+	case ExporterStdout:
+
+		exp, err := stdout.NewExporter(stdout.WithoutMetricExport(), stdout.WithPrettyPrint())
+
+		return exp, nil, err
+
+	case ExporterFile:
+
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot open trace file : %v", err)
+		}
+
+		exp, err := stdout.NewExporter(stdout.WithoutMetricExport(), stdout.WithWriter(file))
+
+		if err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+
+		return exp, file, nil
+
+	case ExporterOTLP:
+
+		opts := []otlp.ExporterOption{otlp.WithAddress(cfg.Endpoint)}
+
+		if cfg.Insecure {
+			opts = append(opts, otlp.WithInsecure())
+		}
+
+		exp, err := otlp.NewExporter(opts...)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot create otlp exporter : %v", err)
+		}
+
+		return exp, nil, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown trace exporter %q (want %v, %v, %v or %v)", cfg.Exporter, ExporterNone, ExporterStdout, ExporterFile, ExporterOTLP)
+}
+
+// Start - starts a span as a child of any span in ctx
+func Start(ctx context.Context, spanName string, attrs ...label.KeyValue) (context.Context, trace.Span) {
+	return global.Tracer(name).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// End - ends span, marking it failed when err is set
+func End(ctx context.Context, span trace.Span, err error) {
+
+	if err != nil {
+		span.RecordError(ctx, err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"grpcourse/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileExporter(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tracing")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "traces.json")
+
+	shutdown, err := Setup(config.Tracing{Exporter: ExporterFile, File: path, SampleRatio: 1}, "test")
+
+	if err != nil {
+		t.Fatalf("cannot set up tracing : %v", err)
+	}
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+
+	End(ctx, child, errors.New("disk full"))
+	parent.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("cannot flush spans : %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`"parent"`, `"child"`, "disk full", parent.SpanContext().TraceID.String()} {
+
+		if !strings.Contains(string(data), want) {
+			t.Errorf("exported spans do not contain %v", want)
+		}
+	}
+}
+
+func TestUnknownExporter(t *testing.T) {
+
+	if _, err := Setup(config.Tracing{Exporter: "zipkin"}, "test"); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
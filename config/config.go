@@ -0,0 +1,625 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config - settings shared by the server and client commands.
+// Values are read from a JSON file and selected fields can be
+// overridden from the environment (secrets mostly).
+type Config struct {
+	Server  Server  `json:"server"`
+	Auth    Auth    `json:"auth"`
+	TLS     TLS     `json:"tls"`
+	Health  Health  `json:"health"`
+	Metrics Metrics `json:"metrics"`
+	Tracing Tracing `json:"tracing"`
+	Gateway Gateway `json:"gateway"`
+	GRPCWeb GRPCWeb `json:"grpc_web"`
+	Admin   Admin   `json:"admin"`
+
+	RateLimit   RateLimit   `json:"rate_limit"`
+	Concurrency Concurrency `json:"concurrency"`
+	Cache       Cache       `json:"cache"`
+	Tenancy     Tenancy     `json:"tenancy"`
+	Store       Store       `json:"store"`
+	Images      Images      `json:"images"`
+}
+
+// Server - where the gRPC server accepts connections
+type Server struct {
+	// Listeners - every listener serves all the services
+	Listeners []Listener `json:"listeners"`
+
+	// SinglePort - also serve the REST gateway, gRPC-Web and /healthz on the
+	// listeners, routed by protocol. /metrics is never served there, only on
+	// metrics.addr. TLS negotiates HTTP/2 with ALPN,
+	// without TLS HTTP/2 runs in clear text (h2c).
+	SinglePort bool `json:"single_port"`
+
+	// DrainTimeout - on SIGINT/SIGTERM, how long in-flight calls may run
+	// before the server stops them
+	DrainTimeout Duration `json:"drain_timeout"`
+}
+
+// Listener - a TCP address, a unix domain socket or an inherited socket
+type Listener struct {
+	// Network - "tcp", "unix" or "fd" (socket passed by systemd, LISTEN_FDS)
+	Network string `json:"network"`
+
+	// Addr - host:port for tcp, the socket path for unix and the descriptor
+	// number or its FileDescriptorName= for fd
+	Addr string `json:"addr"`
+
+	// Mode, Owner, Group - permissions of a unix socket eg. "0660". Local
+	// callers need no client certificate so these are its access control.
+	Mode  string `json:"mode"`
+	Owner string `json:"owner"`
+	Group string `json:"group"`
+
+	// TLS - serve TLS on this listener, unset follows tls.enabled
+	TLS *bool `json:"tls"`
+}
+
+// Secure - reports whether the listener serves TLS
+func (l Listener) Secure(t TLS) bool {
+
+	if l.TLS != nil {
+		return *l.TLS
+	}
+
+	return t.Enabled
+}
+
+// Auth - bearer token (JWT) settings
+type Auth struct {
+	// Enabled - when false no token is required on any method
+	Enabled bool `json:"enabled"`
+
+	// SigningKey - HMAC (HS256) key used to sign and verify tokens
+	SigningKey string `json:"signing_key"`
+
+	// Issuer - expected `iss` claim, empty to accept any issuer
+	Issuer string `json:"issuer"`
+
+	// Public - methods callable without a token. Entries are full method
+	// names eg. "/BlogService/ReadBlog" or a whole service eg. "/GreetService/*"
+	Public []string `json:"public"`
+}
+
+// TLS - transport security settings of the gRPC server
+type TLS struct {
+	// Enabled - serve over TLS
+	Enabled bool `json:"enabled"`
+
+	// Cert, Key - server certificate and its (unencrypted) private key
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+
+	// ClientCA - CA bundle used to verify client certificates. Setting it turns
+	// on mutual TLS: every client must present a certificate signed by this CA.
+	ClientCA string `json:"client_ca"`
+
+	// Access - per method allow/deny lists of client certificate identities.
+	// Keys are full method names or "/Service/*".
+	Access map[string]Access `json:"access"`
+
+	// ReloadInterval - how often the certificate files are checked for changes.
+	// The server also reloads them on SIGHUP.
+	ReloadInterval Duration `json:"reload_interval"`
+}
+
+// Access - client certificate identities allowed or denied on a method.
+// Deny always wins, an empty Allow list allows every verified client.
+type Access struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Health - background checks feeding the grpc.health.v1 service
+type Health struct {
+	// Interval - time between dependency checks (mongo ping)
+	Interval Duration `json:"interval"`
+
+	// Timeout - a check slower than this counts as failed
+	Timeout Duration `json:"timeout"`
+}
+
+// Metrics - prometheus endpoint served next to the gRPC listener
+type Metrics struct {
+	// Addr - listen address of the /metrics HTTP endpoint, empty to disable
+	// it. Metrics name methods and tenants, keep it off public interfaces.
+	Addr string `json:"addr"`
+}
+
+// Tracing - OpenTelemetry span export, shared by the server and client commands
+type Tracing struct {
+	// Exporter - "none", "stdout", "file" or "otlp"
+	Exporter string `json:"exporter"`
+
+	// Endpoint - collector address (host:port) for the otlp exporter
+	Endpoint string `json:"endpoint"`
+
+	// Insecure - talk to the collector without TLS
+	Insecure bool `json:"insecure"`
+
+	// File - path the file exporter appends JSON spans to
+	File string `json:"file"`
+
+	// SampleRatio - fraction of new traces recorded, 1 records all of them.
+	// Calls joining a trace follow the caller's decision.
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// Gateway - REST/JSON gateway in front of the gRPC server
+type Gateway struct {
+	// Addr - listen address of the gateway, empty to not start it with the server
+	Addr string `json:"addr"`
+
+	// Upstream - address of the gRPC server the gateway proxies to
+	Upstream string `json:"upstream"`
+
+	// TLS - serve HTTPS with the server certificate (tls.cert, tls.key)
+	TLS bool `json:"tls"`
+
+	// CA - trusted to verify the upstream when tls.enabled is set
+	CA string `json:"ca"`
+
+	// Cert, Key - client certificate presented to the upstream under mutual TLS
+	Cert string `json:"cert"`
+	Key  string `json:"key"`
+
+	// OpenAPIDir - generated OpenAPI documents, served on /openapi/
+	OpenAPIDir string `json:"openapi_dir"`
+}
+
+// GRPCWeb - HTTP/1.1 listener serving the gRPC services to browsers
+type GRPCWeb struct {
+	// Addr - listen address, empty to disable gRPC-Web
+	Addr string `json:"addr"`
+
+	// TLS - serve HTTPS with the server certificate and client CA (tls section)
+	TLS bool `json:"tls"`
+
+	// AllowedOrigins - CORS origins browsers may call from, "*" for any
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// Admin - operator listener: pprof, channelz, log level and calls in
+// progress. Every request needs a token with the admin claim (make token).
+type Admin struct {
+	// Addr - listen address, empty to disable it. Keep it off public interfaces.
+	// Needs auth with a signing key, admin tokens are all that guard it.
+	Addr string `json:"addr"`
+
+	// TLS - serve HTTPS with the server certificate and client CA (tls section)
+	TLS bool `json:"tls"`
+}
+
+// RateLimit - token buckets per caller. Callers are told apart by token
+// subject, then client certificate, then IP address.
+type RateLimit struct {
+	Enabled bool `json:"enabled"`
+
+	// Methods - limits by full method name eg. "/BlogService/ListBlog", whole
+	// service eg. "/BlogService/*" or "*". The most specific entry applies and
+	// methods sharing an entry share the caller's budget.
+	Methods map[string]Limit `json:"methods"`
+
+	// Gateways - peers trusted to name the client in x-forwarded-for: IP
+	// addresses of REST gateways, or the identity of their client
+	// certificate. Everyone else is limited by their own address.
+	Gateways []string `json:"gateways"`
+}
+
+// Limit - what a single caller may do, zero values mean no limit
+type Limit struct {
+	// Rate - calls per second, Burst - calls allowed back to back
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+
+	// Concurrent - calls (eg. upload streams) open at the same time
+	Concurrent int `json:"concurrent"`
+
+	// BytesPerSecond - request bandwidth, uploads wait rather than fail
+	BytesPerSecond int `json:"bytes_per_second"`
+}
+
+// Concurrency - adaptive limits on the calls in progress per group of
+// methods. Calls over the limit fail fast with UNAVAILABLE.
+type Concurrency struct {
+	Enabled bool `json:"enabled"`
+
+	// Groups - methods sharing a limit by group name. Methods in no group are not limited.
+	Groups map[string]ConcurrencyGroup `json:"groups"`
+
+	// Exempt - methods never shed, eg. health checks. Same forms as Methods.
+	Exempt []string `json:"exempt"`
+}
+
+// ConcurrencyGroup - the limit grows by one per round of calls finishing
+// under Target and shrinks by a tenth when they are slower or time out (AIMD)
+type ConcurrencyGroup struct {
+	// Methods - full method names or whole services eg. "/GreetService/*"
+	Methods []string `json:"methods"`
+
+	// Initial, Min, Max - starting limit and its bounds
+	Initial int `json:"initial"`
+	Min     int `json:"min"`
+	Max     int `json:"max"`
+
+	// Target - latency the group should stay under, zero to only back off on
+	// timeouts. Streams count until their first message, without the time
+	// spent waiting on the client.
+	Target Duration `json:"target"`
+}
+
+// Cache - read-through cache in front of ReadBlog and ListBlog. Updates
+// and deletes invalidate the entries they touch.
+type Cache struct {
+	// Backend - "memory" (in-process LRU), "redis" (shared by every instance) or "none"
+	Backend string `json:"backend"`
+
+	// Size - entries kept by the memory backend
+	Size int `json:"size"`
+
+	// BlogTTL, ListTTL - how long a blog and the blog list are served from the cache
+	BlogTTL Duration `json:"blog_ttl"`
+	ListTTL Duration `json:"list_ttl"`
+
+	Redis Redis `json:"redis"`
+}
+
+// Redis - connection of the redis cache backend. The password can also be
+// set with GRPCOURSE_REDIS_PASSWORD.
+type Redis struct {
+	Addr     string `json:"addr"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+
+	// Prefix - prepended to every key
+	Prefix string `json:"prefix"`
+}
+
+// Tenancy - teams sharing the server, each with its own database and image
+// key prefix. Tenants are provisioned with the tenant command.
+type Tenancy struct {
+	Enabled bool `json:"enabled"`
+
+	// Header - metadata key naming the tenant, honoured for admin tokens
+	// only. A `tenant` claim in the token takes precedence and the header
+	// may not contradict it.
+	Header string `json:"header"`
+
+	// Default - tenant of calls naming none, empty to reject them
+	Default string `json:"default"`
+
+	// DatabasePrefix - tenant databases are named prefix + tenant id
+	DatabasePrefix string `json:"database_prefix"`
+
+	// Exempt - methods served without a tenant. Same forms as auth.public.
+	Exempt []string `json:"exempt"`
+}
+
+// Store - where blogs and the audit trail are kept
+type Store struct {
+	// Backend - "mongo", or "bolt" for a single file and no database server
+	Backend string `json:"backend"`
+
+	// Path - file of the bolt backend
+	Path string `json:"path"`
+}
+
+// Check - the backend is known and supports the other settings
+func (s Store) Check(t Tenancy) error {
+
+	switch s.Backend {
+	case "mongo":
+	case "bolt":
+
+		if t.Enabled {
+			return fmt.Errorf("tenancy needs the mongo store")
+		}
+
+	default:
+		return fmt.Errorf("unknown store backend %q, use mongo or bolt", s.Backend)
+	}
+
+	return nil
+}
+
+// Images - where blog images are kept. The blog documents hold image keys,
+// the same for every backend.
+type Images struct {
+	// Backend - "disk", "gridfs" (the mongo database, backed up with the blogs)
+	// or "s3"
+	Backend string `json:"backend"`
+
+	// Dir - root directory of the disk backend
+	Dir string `json:"dir"`
+
+	// Bucket - GridFS bucket name, its collections are <bucket>.files and <bucket>.chunks
+	Bucket string `json:"bucket"`
+
+	S3 S3 `json:"s3"`
+}
+
+// S3 - bucket of the s3 images backend, on AWS or any S3 compatible store.
+// Without keys the usual AWS environment, shared config and instance roles
+// apply. The keys can also be set with GRPCOURSE_S3_ACCESS_KEY and
+// GRPCOURSE_S3_SECRET_KEY.
+type S3 struct {
+	Bucket string `json:"bucket"`
+
+	// Prefix - prepended to every image key eg. "grpcourse/"
+	Prefix string `json:"prefix"`
+
+	// Endpoint - empty for AWS, eg. "http://localhost:9000" for MinIO
+	Endpoint string `json:"endpoint"`
+	Region   string `json:"region"`
+
+	// PathStyle - bucket in the URL path rather than the host name, most
+	// S3 compatible stores need it
+	PathStyle bool `json:"path_style"`
+
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+
+	// PartSize - uploads larger than this many bytes go in parts, at least 5 MiB
+	PartSize int `json:"part_size"`
+
+	// Checksums - have the store verify and keep a SHA-256 of every upload.
+	// Content-MD5 is always sent, turn this off for stores without
+	// x-amz-checksum-sha256 support.
+	Checksums bool `json:"checksums"`
+}
+
+// minPartSize - the smallest part S3 accepts, except for the last one
+const minPartSize = 5 << 20
+
+// Default - settings used when no config file is present
+func Default() *Config {
+
+	return &Config{
+		Server: Server{
+			Listeners:  []Listener{{Network: "tcp", Addr: ":50051"}},
+			SinglePort: true,
+
+			DrainTimeout: Duration(30 * time.Second),
+		},
+		Auth: Auth{
+			Enabled: true,
+			Issuer:  "grpcourse",
+			Public: []string{
+				"/GreetService/*",
+				"/BlogService/ReadBlog",
+				"/BlogService/ListBlog",
+				"/BlogService/DownloadImage",
+				"/grpc.reflection.v1alpha.ServerReflection/*",
+				"/grpc.health.v1.Health/*",
+			},
+		},
+		TLS: TLS{
+			Enabled: true,
+			Cert:    "ssl/server.crt",
+			Key:     "ssl/server.pem",
+
+			ReloadInterval: Duration(30 * time.Second),
+		},
+		Health: Health{
+			Interval: Duration(5 * time.Second),
+			Timeout:  Duration(2 * time.Second),
+		},
+		// never on the API port, loopback only by default
+		Metrics: Metrics{Addr: "localhost:9090"},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "localhost:55680",
+			File:        "traces.json",
+			SampleRatio: 1,
+		},
+		Gateway: Gateway{
+			Upstream:   "localhost:50051",
+			CA:         "ssl/ca.crt",
+			OpenAPIDir: "data/protos/openapi",
+		},
+		GRPCWeb: GRPCWeb{
+			AllowedOrigins: []string{"http://localhost:3000"},
+		},
+		RateLimit: RateLimit{
+			Enabled: true,
+			Methods: map[string]Limit{
+				"/BlogService/ListBlog":   {Rate: 5, Burst: 10},
+				"/BlogService/CreateBlog": {Rate: 1, Burst: 5, Concurrent: 2, BytesPerSecond: 512 << 10},
+				"/BlogService/UpdateBlog": {Rate: 1, Burst: 5, BytesPerSecond: 512 << 10},
+			},
+			// the gateway started with the server
+			Gateways: []string{"127.0.0.1", "::1"},
+		},
+		Concurrency: Concurrency{
+			Enabled: true,
+			Groups: map[string]ConcurrencyGroup{
+				"writes": {
+					Methods: []string{"/BlogService/CreateBlog", "/BlogService/UpdateBlog", "/BlogService/DeleteBlog"},
+					Initial: 8, Min: 2, Max: 64,
+					Target: Duration(5 * time.Second),
+				},
+				"reads": {
+					Methods: []string{"/BlogService/ReadBlog", "/BlogService/ListBlog", "/BlogService/DownloadImage"},
+					Initial: 32, Min: 4, Max: 512,
+					Target: Duration(500 * time.Millisecond),
+				},
+				// streams and deliberately slow demos - latency says nothing about load
+				"greet": {
+					Methods: []string{"/GreetService/*"},
+					Initial: 64, Min: 8, Max: 256,
+				},
+			},
+			Exempt: []string{"/grpc.health.v1.Health/*", "/grpc.reflection.v1alpha.ServerReflection/*"},
+		},
+		Cache: Cache{
+			Backend: "memory",
+			Size:    10000,
+			BlogTTL: Duration(5 * time.Minute),
+			ListTTL: Duration(30 * time.Second),
+			Redis: Redis{
+				Addr:   "localhost:6379",
+				Prefix: "grpcourse:",
+			},
+		},
+		Tenancy: Tenancy{
+			Header:         "x-tenant-id",
+			DatabasePrefix: "grpcourse_",
+			Exempt: []string{
+				"/GreetService/*",
+				"/grpc.reflection.v1alpha.ServerReflection/*",
+				"/grpc.health.v1.Health/*",
+			},
+		},
+		Store: Store{
+			Backend: "mongo",
+			Path:    "data/grpcourse.db",
+		},
+		Images: Images{
+			Backend: "disk",
+			Dir:     "data/images",
+			Bucket:  "images",
+			S3: S3{
+				Region:    "us-east-1",
+				PartSize:  8 << 20,
+				Checksums: true,
+			},
+		},
+	}
+}
+
+// Load - reads config from path on top of the defaults. A missing file is not
+// an error, the defaults are used - auth is on in them, so they still need a
+// signing key from GRPCOURSE_JWT_KEY.
+func Load(path string) (*Config, error) {
+
+	cfg := Default()
+
+	file, err := os.Open(path)
+
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot open config file %v : %v", path, err)
+	}
+
+	if err == nil {
+
+		defer file.Close()
+
+		if err := json.NewDecoder(file).Decode(cfg); err != nil {
+			return nil, fmt.Errorf("cannot parse config file %v : %v", path, err)
+		}
+	}
+
+	// environment overrides
+	if key := os.Getenv("GRPCOURSE_JWT_KEY"); key != "" {
+		cfg.Auth.SigningKey = key
+	}
+
+	if pass := os.Getenv("GRPCOURSE_REDIS_PASSWORD"); pass != "" {
+		cfg.Cache.Redis.Password = pass
+	}
+
+	if key := os.Getenv("GRPCOURSE_S3_ACCESS_KEY"); key != "" {
+		cfg.Images.S3.AccessKey = key
+	}
+
+	if key := os.Getenv("GRPCOURSE_S3_SECRET_KEY"); key != "" {
+		cfg.Images.S3.SecretKey = key
+	}
+
+	for _, l := range cfg.Server.Listeners {
+
+		if l.Secure(cfg.TLS) && !cfg.TLS.Enabled {
+			return nil, fmt.Errorf("listener %v asks for tls but tls.enabled is off (no certificate)", l.Addr)
+		}
+	}
+
+	switch cfg.Cache.Backend {
+	case "memory", "redis", "none":
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q, use memory, redis or none", cfg.Cache.Backend)
+	}
+
+	if err := cfg.Store.Check(cfg.Tenancy); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Images.Backend {
+	case "disk", "gridfs":
+	case "s3":
+
+		if cfg.Images.S3.Bucket == "" {
+			return nil, fmt.Errorf("images.s3.bucket is required by the s3 images backend")
+		}
+
+		if cfg.Images.S3.PartSize < minPartSize {
+			return nil, fmt.Errorf("images.s3.part_size %d is below the 5 MiB S3 minimum", cfg.Images.S3.PartSize)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown images backend %q, use disk, gridfs or s3", cfg.Images.Backend)
+	}
+
+	// a ticker panics on anything else
+	if cfg.Health.Interval <= 0 || cfg.Health.Timeout <= 0 {
+		return nil, fmt.Errorf("health.interval and health.timeout must be positive")
+	}
+
+	if cfg.Tenancy.Enabled && cfg.Tenancy.Header == "" {
+		return nil, fmt.Errorf("tenancy is enabled but tenancy.header is empty")
+	}
+
+	for name, g := range cfg.Concurrency.Groups {
+
+		if g.Min < 1 || g.Min > g.Initial || g.Initial > g.Max {
+			return nil, fmt.Errorf("concurrency group %v needs 1 <= min <= initial <= max", name)
+		}
+	}
+
+	// admin tokens are the only thing guarding pprof and channelz
+	if cfg.Admin.Addr != "" && (!cfg.Auth.Enabled || cfg.Auth.SigningKey == "") {
+		return nil, fmt.Errorf("admin.addr needs auth enabled with a signing key")
+	}
+
+	if cfg.Auth.Enabled && cfg.Auth.SigningKey == "" {
+		return nil, fmt.Errorf("a signing key is required while auth is enabled : set GRPCOURSE_JWT_KEY (make key writes a local one) or auth.signing_key")
+	}
+
+	return cfg, nil
+}
+
+// Duration - time.Duration written as a string in JSON eg. "30s", "5m"
+type Duration time.Duration
+
+// UnmarshalJSON -
+func (d *Duration) UnmarshalJSON(b []byte) error {
+
+	var s string
+
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string eg. \"30s\" : %v", err)
+	}
+
+	v, err := time.ParseDuration(s)
+
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+// MarshalJSON -
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache - byte values with a time to live per entry
+type Cache interface {
+	// Get - the value under key, false when missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set - stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete - removes keys, missing ones are ignored
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// lookup outcomes reported to the Observer
+const (
+	Hit   = "hit"
+	Miss  = "miss"
+	Error = "error"
+)
+
+// Observer - receives the outcome of every lookup (eg. the metrics package)
+type Observer func(name, result string)
+
+var (
+	observerMu sync.RWMutex
+	observer   Observer
+)
+
+// SetObserver - reports every lookup to o
+func SetObserver(o Observer) {
+
+	observerMu.Lock()
+	defer observerMu.Unlock()
+
+	observer = o
+}
+
+func observe(name, result string) {
+
+	observerMu.RLock()
+	o := observer
+	observerMu.RUnlock()
+
+	if o != nil {
+		o(name, result)
+	}
+}
+
+// fetchTimeout - a shared fetch runs apart from its callers, this bounds it
+const fetchTimeout = 10 * time.Second
+
+// Loader - read-through access to one kind of entry. Concurrent misses on
+// the same key share a single fetch. A nil cache only collapses the misses.
+type Loader struct {
+	name  string
+	cache Cache
+	ttl   time.Duration
+	group singleflight.Group
+
+	// gen is bumped by Invalidate - fetches started before it do not store
+	// their value. Stores hold mu for reading from the gen check to the end
+	// of Set, so an Invalidate either comes before the check or after Set,
+	// and then deletes what was stored. Loaders in other processes sharing
+	// a cache (eg. redis) may still store a value read before the change,
+	// it lives until its ttl.
+	mu  sync.RWMutex
+	gen uint64
+}
+
+// NewLoader - name labels the lookups, ttl applies to every entry it stores
+func NewLoader(name string, c Cache, ttl time.Duration) *Loader {
+	return &Loader{name: name, cache: c, ttl: ttl}
+}
+
+// Load - the cached value of key, or the result of fetch which is then cached.
+// A failing cache is skipped rather than failing the read. The shared fetch
+// gets the values of the first caller's ctx but not its deadline or
+// cancellation, which only end the wait of the caller they belong to.
+func (l *Loader) Load(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+
+	if l.cache != nil {
+
+		value, ok, err := l.cache.Get(ctx, key)
+
+		switch {
+		case err != nil:
+			observe(l.name, Error)
+
+		case ok:
+			observe(l.name, Hit)
+			return value, nil
+
+		default:
+			observe(l.name, Miss)
+		}
+	}
+
+	ch := l.group.DoChan(key, func() (interface{}, error) {
+
+		ctx, cancel := context.WithTimeout(detach(ctx), fetchTimeout)
+		defer cancel()
+
+		l.mu.RLock()
+		gen := l.gen
+		l.mu.RUnlock()
+
+		value, err := fetch(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if l.cache != nil {
+			l.store(ctx, gen, key, value)
+		}
+
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+
+	case res := <-ch:
+
+		if res.Err != nil {
+			return nil, res.Err
+		}
+
+		return res.Val.([]byte), nil
+	}
+}
+
+// store - caches value unless an Invalidate ran since generation gen
+func (l *Loader) store(ctx context.Context, gen uint64, key string, value []byte) {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.gen != gen {
+		return
+	}
+
+	if err := l.cache.Set(ctx, key, value, l.ttl); err != nil {
+		observe(l.name, Error)
+	}
+}
+
+// Invalidate - drops keys so the next Load fetches them again
+func (l *Loader) Invalidate(ctx context.Context, keys ...string) error {
+
+	// waits for stores in progress, later ones see the new generation
+	l.mu.Lock()
+	l.gen++
+	l.mu.Unlock()
+
+	// a fetch in flight read the old value, the next miss starts a new one
+	for _, key := range keys {
+		l.group.Forget(key)
+	}
+
+	if l.cache == nil {
+		return nil
+	}
+
+	return l.cache.Delete(ctx, keys...)
+}
+
+// detached - the values of a context without its deadline and cancellation
+type detached struct {
+	context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detached{ctx}
+}
+
+// Deadline -
+func (detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done -
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+// Err -
+func (detached) Err() error {
+	return nil
+}
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v7"
+)
+
+func TestLRU(t *testing.T) {
+
+	ctx := context.Background()
+
+	c := NewLRU(2)
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+
+	// a becomes the most recently used, so adding c evicts b
+	c.Get(ctx, "a")
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b should have been evicted")
+	}
+
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("unexpected a %q %v", v, ok)
+	}
+
+	c.Set(ctx, "short", []byte("x"), -time.Second)
+
+	if _, ok, _ := c.Get(ctx, "short"); ok {
+		t.Error("expired entries must not be returned")
+	}
+}
+
+func TestRedis(t *testing.T) {
+
+	srv, err := miniredis.Run()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	c := NewRedis(redis.NewClient(&redis.Options{Addr: srv.Addr()}), "test:")
+
+	if err := c.Set(ctx, "blog:1", []byte("hello"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok, err := c.Get(ctx, "blog:1"); err != nil || !ok || string(v) != "hello" {
+		t.Fatalf("unexpected value %q %v %v", v, ok, err)
+	}
+
+	if !srv.Exists("test:blog:1") || srv.TTL("test:blog:1") != time.Minute {
+		t.Errorf("expected a prefixed key with a ttl")
+	}
+
+	srv.FastForward(2 * time.Minute)
+
+	if _, ok, err := c.Get(ctx, "blog:1"); ok || err != nil {
+		t.Errorf("expected the entry to expire : %v", err)
+	}
+}
+
+func TestLoaderCollapsesMisses(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := NewLoader("blog", NewLRU(10), time.Minute)
+
+	var fetches int32
+
+	release := make(chan struct{})
+
+	fetch := func(ctx context.Context) ([]byte, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return []byte("v1"), nil
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if v, err := l.Load(ctx, "blog:1", fetch); err != nil || string(v) != "v1" {
+				t.Errorf("unexpected load %q %v", v, err)
+			}
+		}()
+	}
+
+	// let the callers pile up on the first fetch
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("expected a single fetch, got %v", n)
+	}
+
+	// cached now, then gone after an invalidation
+	l.Load(ctx, "blog:1", func(ctx context.Context) ([]byte, error) {
+		t.Error("expected a hit")
+		return nil, nil
+	})
+
+	l.Invalidate(ctx, "blog:1")
+
+	v, _ := l.Load(ctx, "blog:1", func(ctx context.Context) ([]byte, error) {
+		return []byte("v2"), nil
+	})
+
+	if string(v) != "v2" {
+		t.Errorf("expected a fresh value, got %q", v)
+	}
+}
+
+func TestLoaderOutlivesFirstCaller(t *testing.T) {
+
+	l := NewLoader("blog", NewLRU(10), time.Minute)
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	fetch := func(ctx context.Context) ([]byte, error) {
+
+		close(started)
+
+		select {
+		case <-release:
+			return []byte("v1"), nil
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	first, cancel := context.WithCancel(context.Background())
+
+	errs := make(chan error, 1)
+
+	go func() {
+		_, err := l.Load(first, "blog:1", fetch)
+		errs <- err
+	}()
+
+	<-started
+
+	second := make(chan []byte, 1)
+
+	go func() {
+		v, _ := l.Load(context.Background(), "blog:1", fetch)
+		second <- v
+	}()
+
+	// the first caller gives up, the fetch and the second caller carry on
+	cancel()
+
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("expected the first caller to be cancelled, got %v", err)
+	}
+
+	close(release)
+
+	if v := <-second; string(v) != "v1" {
+		t.Errorf("expected the second caller to get the value, got %q", v)
+	}
+}
+
+func TestLoaderInvalidateDuringFetch(t *testing.T) {
+
+	ctx := context.Background()
+
+	l := NewLoader("blog", NewLRU(10), time.Minute)
+
+	l.Load(ctx, "blog:1", func(ctx context.Context) ([]byte, error) {
+
+		// the blog changes while the old value is on its way
+		l.Invalidate(ctx, "blog:1")
+
+		return []byte("stale"), nil
+	})
+
+	v, _ := l.Load(ctx, "blog:1", func(ctx context.Context) ([]byte, error) {
+		return []byte("fresh"), nil
+	})
+
+	if string(v) != "fresh" {
+		t.Errorf("a value read before the invalidation was cached : %q", v)
+	}
+}
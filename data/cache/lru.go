@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU - in-process Cache holding at most size entries, the least recently
+// used entry is evicted first
+type LRU struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRU - returns an empty LRU for size entries
+func NewLRU(size int) *LRU {
+
+	if size < 1 {
+		size = 1
+	}
+
+	return &LRU{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Get -
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*lruEntry)
+
+	if time.Now().After(entry.expires) {
+		c.remove(el)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(el)
+
+	return entry.value, true, nil
+}
+
+// Set -
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+
+	if el, ok := c.entries[key]; ok {
+
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+
+		c.order.MoveToFront(el)
+
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete -
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+	}
+
+	return nil
+}
+
+// Len - entries held, expired ones included until they are looked up or evicted
+func (c *LRU) Len() int {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove - called with mu held
+func (c *LRU) remove(el *list.Element) {
+
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// Redis - Cache shared by every server instance. Expiry is left to redis.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis - keys are stored under prefix so several apps can share a database
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// Get -
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+
+	value, err := c.client.WithContext(ctx).Get(c.prefix + key).Bytes()
+
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set -
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.WithContext(ctx).Set(c.prefix+key, value, ttl).Err()
+}
+
+// Delete -
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+
+	return c.client.WithContext(ctx).Del(prefixed...).Err()
+}
@@ -16,7 +16,7 @@ var (
 
 func init() {
 
-	client, err = mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	client, err = mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017").SetMonitor(monitor()))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 
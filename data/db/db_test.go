@@ -4,12 +4,21 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestMongoDB(t *testing.T) {
 
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// CI and laptops without mongo use the bolt store instead
+	if err := GetDB().Client().Ping(ctx, nil); err != nil {
+		t.Skipf("mongodb is not reachable : %v", err)
+	}
+
 	var collection = GetDB().Collection("test")
 
 	res, err := collection.InsertOne(context.Background(), bson.M{"hello": "world"})
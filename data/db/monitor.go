@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/api/global"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/semconv"
+)
+
+// Observer - receives the outcome of every command sent to mongo
+type Observer func(collection, command string, took time.Duration, failed bool)
+
+var (
+	observerMu sync.RWMutex
+	observer   Observer
+
+	// request id -> commands in flight
+	pending sync.Map
+)
+
+// command - a started command waiting for its outcome
+type command struct {
+	collection string
+	span       trace.Span
+}
+
+// SetObserver - reports every mongo command to o (eg. the metrics package)
+func SetObserver(o Observer) {
+
+	observerMu.Lock()
+	defer observerMu.Unlock()
+
+	observer = o
+}
+
+// monitor - command monitor installed on the client
+func monitor() *event.CommandMonitor {
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+
+			coll := collectionName(e)
+
+			// child span of the RPC that issued the command
+			_, span := global.Tracer("grpcourse/data/db").Start(ctx, "mongo."+e.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongodb,
+					semconv.DBNameKey.String(e.DatabaseName),
+					semconv.DBOperationKey.String(e.CommandName),
+					semconv.DBMongoDBCollectionKey.String(coll),
+				),
+			)
+
+			pending.Store(e.RequestID, command{collection: coll, span: span})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finished(e.CommandFinishedEvent, false)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+
+			if v, ok := pending.Load(e.RequestID); ok {
+				cmd := v.(command)
+				cmd.span.SetStatus(codes.Error, e.Failure)
+			}
+
+			finished(e.CommandFinishedEvent, true)
+		},
+	}
+}
+
+func finished(e event.CommandFinishedEvent, failed bool) {
+
+	v, ok := pending.Load(e.RequestID)
+
+	if !ok {
+		return
+	}
+
+	pending.Delete(e.RequestID)
+
+	cmd := v.(command)
+	cmd.span.End()
+
+	observerMu.RLock()
+	o := observer
+	observerMu.RUnlock()
+
+	if o != nil {
+		o(cmd.collection, e.CommandName, time.Duration(e.DurationNanos), failed)
+	}
+}
+
+// collectionName - collection a command targets, the database for
+// database level commands (eg. ping)
+func collectionName(e *event.CommandStartedEvent) string {
+
+	key := e.CommandName
+
+	// getMore carries the cursor id under its name
+	if key == "getMore" {
+		key = "collection"
+	}
+
+	if name, ok := e.Command.Lookup(key).StringValueOK(); ok {
+		return name
+	}
+
+	return e.DatabaseName
+}
@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// orientationTag - EXIF tag of the orientation in IFD0
+const orientationTag = 0x0112
+
+// Orientation - the EXIF orientation of a JPEG, 1 (upright) when there is
+// none or it cannot be read
+func Orientation(data []byte) int {
+
+	// 1. walk the segments up to the image data looking for APP1 "Exif"
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+
+		// start of scan, the metadata is behind us
+		if marker == 0xDA {
+			return 1
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+size]
+
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		i += 2 + size
+	}
+
+	return 1
+}
+
+// tiffOrientation - reads the orientation entry of the first IFD of a TIFF header
+func tiffOrientation(tiff []byte) int {
+
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+
+	// 2. entries are 12 bytes : tag, type, count then the value itself when it fits
+	for e := 0; e < entries; e++ {
+
+		at := ifd + 2 + e*12
+
+		if at+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[at:]) == orientationTag {
+
+			if o := int(order.Uint16(tiff[at+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+
+			return 1
+		}
+	}
+
+	return 1
+}
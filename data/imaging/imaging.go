@@ -0,0 +1,207 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	// formats accepted on upload
+	_ "image/gif"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// ErrNotImage - the upload is not a JPEG, PNG or GIF image
+var ErrNotImage = errors.New("not a JPEG, PNG or GIF image")
+
+// MaxPixels - bigger images are refused before decoding. A small file may
+// claim huge dimensions and decode to gigabytes.
+const MaxPixels = 16 << 20
+
+// maxDecodes - images decoded at the same time. Each takes up to 4 bytes a
+// pixel, and again as much while turning it upright.
+const maxDecodes = 4
+
+var decodes = make(chan struct{}, maxDecodes)
+
+// jpegQuality - of every JPEG written
+const jpegQuality = 85
+
+// Spec - a rendition of an upload, downscaled to fit MaxSide pixels.
+// Zero keeps the original size.
+type Spec struct {
+	Name    string
+	MaxSide int
+}
+
+// Original - name of the full size variant
+const Original = "original"
+
+// Variants - what every upload is turned into
+var Variants = []Spec{
+	{Name: "thumbnail", MaxSide: 160},
+	{Name: "medium", MaxSide: 800},
+	{Name: Original},
+}
+
+// Image - an encoded rendition
+type Image struct {
+	Name        string
+	Data        []byte
+	ContentType string
+
+	// Ext - file extension of the format eg. ".jpg"
+	Ext string
+
+	Width, Height int
+}
+
+// Process - decodes data and encodes it once per spec, upright and without
+// metadata. JPEGs stay JPEG, everything else becomes PNG.
+func Process(data []byte, specs []Spec) ([]Image, error) {
+
+	// 1. sniff the format and size from the header only
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, fmt.Errorf("%dx%d image is over %d pixels", cfg.Width, cfg.Height, MaxPixels)
+	}
+
+	// 2. a slot to decode in, uploads beyond that wait their turn
+	decodes <- struct{}{}
+	defer func() { <-decodes }()
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	// 3. cameras store pixels as shot and the rotation in EXIF, which goes
+	// away with the rest of the metadata when re-encoding
+	if format == "jpeg" {
+		src = orient(src, Orientation(data))
+	}
+
+	// 4. one encoding per spec
+	var images []Image
+
+	for _, spec := range specs {
+
+		img := resize(src, spec.MaxSide)
+
+		out := Image{Name: spec.Name, Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+
+		var buf bytes.Buffer
+
+		if format == "jpeg" {
+			out.ContentType, out.Ext = "image/jpeg", ".jpg"
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			out.ContentType, out.Ext = "image/png", ".png"
+			err = png.Encode(&buf, img)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %v : %v", spec.Name, err)
+		}
+
+		out.Data = buf.Bytes()
+
+		images = append(images, out)
+	}
+
+	return images, nil
+}
+
+// resize - img scaled down to fit maxSide, never up
+func resize(img image.Image, maxSide int) image.Image {
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if maxSide == 0 || (w <= maxSide && h <= maxSide) {
+		return img
+	}
+
+	if w >= h {
+		w, h = maxSide, max(1, h*maxSide/w)
+	} else {
+		w, h = max(1, w*maxSide/h), maxSide
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+
+	return dst
+}
+
+// orient - img turned upright according to an EXIF orientation (1 to 8)
+func orient(img image.Image, orientation int) image.Image {
+
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	b := img.Bounds()
+
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+
+	// 5 to 8 turn the image on its side
+	dw, dh := w, h
+
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+
+		for x := 0; x < w; x++ {
+
+			var dx, dy int
+
+			switch orientation {
+			case 2: // mirrored
+				dx, dy = w-1-x, y
+			case 3: // upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // upside down, mirrored
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // needs a quarter turn clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // needs a quarter turn anticlockwise
+				dx, dy = y, w-1-x
+			}
+
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+
+	return dst
+}
+
+func max(a, b int) int {
+
+	if a > b {
+		return a
+	}
+
+	return b
+}
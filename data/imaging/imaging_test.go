@@ -0,0 +1,153 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// photo - a 400x200 JPEG, red on the left half, with an EXIF segment
+// holding orientation and a GPS marker
+func photo(t *testing.T, orientation uint16) []byte {
+
+	img := image.NewRGBA(image.Rect(0, 0, 400, 200))
+
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+
+			c := color.RGBA{0, 0, 255, 255}
+
+			if x < 200 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("cannot encode : %v", err)
+	}
+
+	// little endian TIFF with one IFD entry, followed by a fake GPS payload
+	tiff := []byte("II*\x00\x08\x00\x00\x00\x01\x00\x12\x01\x03\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00GPS 51.5N 0.12W")
+	binary.LittleEndian.PutUint16(tiff[18:], orientation)
+
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(app1)+2))
+
+	jpg := buf.Bytes()
+
+	return append(append(append([]byte{}, jpg[:2]...), append(seg, app1...)...), jpg[2:]...)
+}
+
+func TestOrientation(t *testing.T) {
+
+	for _, o := range []uint16{1, 3, 6, 8} {
+
+		if got := Orientation(photo(t, o)); got != int(o) {
+			t.Errorf("expected orientation %d, got %d", o, got)
+		}
+	}
+
+	if got := Orientation([]byte("not a jpeg")); got != 1 {
+		t.Errorf("expected 1 without exif, got %d", got)
+	}
+}
+
+func TestProcess(t *testing.T) {
+
+	data := photo(t, 6)
+
+	images, err := Process(data, Variants)
+
+	if err != nil {
+		t.Fatalf("cannot process : %v", err)
+	}
+
+	if len(images) != len(Variants) {
+		t.Fatalf("expected %d variants, got %d", len(Variants), len(images))
+	}
+
+	for _, img := range images {
+
+		// 1. metadata is gone
+		if bytes.Contains(img.Data, []byte("Exif")) || bytes.Contains(img.Data, []byte("GPS")) {
+			t.Errorf("%v : metadata left in the output", img.Name)
+		}
+
+		// 2. turned a quarter clockwise, the red half is now on top
+		decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+
+		if err != nil {
+			t.Fatalf("%v : cannot decode output : %v", img.Name, err)
+		}
+
+		b := decoded.Bounds()
+
+		if b.Dx() != img.Width || b.Dy() != img.Height || img.Width > img.Height {
+			t.Errorf("%v : expected a portrait %dx%d image, got %v", img.Name, img.Width, img.Height, b)
+		}
+
+		if r, _, bl, _ := decoded.At(b.Dx()/2, b.Dy()/4).RGBA(); r < bl {
+			t.Errorf("%v : expected red on top", img.Name)
+		}
+	}
+
+	// 3. sizes
+	for i, want := range [][2]int{{80, 160}, {200, 400}, {200, 400}} {
+
+		if images[i].Width != want[0] || images[i].Height != want[1] {
+			t.Errorf("%v : expected %dx%d, got %dx%d", images[i].Name, want[0], want[1], images[i].Width, images[i].Height)
+		}
+	}
+}
+
+func TestProcessKeepsPNG(t *testing.T) {
+
+	var buf bytes.Buffer
+
+	png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1000, 10)))
+
+	images, err := Process(buf.Bytes(), Variants)
+
+	if err != nil {
+		t.Fatalf("cannot process : %v", err)
+	}
+
+	if images[0].ContentType != "image/png" || images[0].Ext != ".png" || images[0].Width != 160 || images[0].Height != 1 {
+		t.Errorf("unexpected thumbnail %v %v %dx%d", images[0].ContentType, images[0].Ext, images[0].Width, images[0].Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+
+	if _, err := Process([]byte("#!/bin/sh\nrm -rf /"), Variants); err != ErrNotImage {
+		t.Errorf("expected ErrNotImage, got %v", err)
+	}
+
+	// a valid header claiming 100000x100000 pixels
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	ihdr[8], ihdr[9] = 8, 2
+
+	chunk := append([]byte("IHDR"), ihdr...)
+
+	bomb := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), chunk...)
+	bomb = append(bomb, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(bomb[len(bomb)-4:], crc32.ChecksumIEEE(chunk))
+
+	if _, err := Process(bomb, Variants); err == nil || err == ErrNotImage {
+		t.Errorf("expected the pixel limit to refuse it, got %v", err)
+	}
+}
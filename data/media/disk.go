@@ -0,0 +1,117 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Disk - images as files under a directory
+type Disk struct {
+	root string
+}
+
+// NewDisk - returns a Store keeping images under root
+func NewDisk(root string) *Disk {
+	return &Disk{root: root}
+}
+
+func (d *Disk) file(key string) (string, error) {
+
+	if !ValidKey(key) {
+		return "", fmt.Errorf("invalid image key %q", key)
+	}
+
+	return filepath.Join(d.root, filepath.FromSlash(key)), nil
+}
+
+// Put - writes a temporary file next to the target and renames it into place
+func (d *Disk) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+
+	p, err := d.file(key)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p), ".upload-*")
+
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(tmp, r)
+
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), p)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Open -
+func (d *Disk) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	p, err := d.file(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(p)
+
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+// Delete -
+func (d *Disk) Delete(ctx context.Context, key string) error {
+
+	p, err := d.file(key)
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// DeletePrefix - prefix must name a directory eg. "team-a/"
+func (d *Disk) DeletePrefix(ctx context.Context, prefix string) error {
+
+	p, err := d.file(path.Clean(prefix))
+
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(p)
+}
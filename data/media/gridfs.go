@@ -0,0 +1,112 @@
+package media
+
+import (
+	"context"
+	"io"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFS - images in a mongo GridFS bucket, replicated and backed up with
+// the documents. The key is the file name.
+type GridFS struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFS - returns a Store on the bucket named name (<name>.files, <name>.chunks) of db
+func NewGridFS(db *mongo.Database, name string) (*GridFS, error) {
+
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName(name))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &GridFS{bucket: bucket}, nil
+}
+
+// Put - streams r into chunks as it is read. Chunks already written are
+// removed when r fails.
+func (g *GridFS) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+
+	up, err := g.bucket.OpenUploadStreamWithID(primitive.NewObjectID(), key)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// the driver takes deadlines rather than contexts
+	if deadline, ok := ctx.Deadline(); ok {
+		up.SetWriteDeadline(deadline)
+	}
+
+	n, err := io.Copy(up, r)
+
+	if err != nil {
+		up.Abort()
+		return 0, err
+	}
+
+	return n, up.Close()
+}
+
+// Open - the newest file named key
+func (g *GridFS) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	down, err := g.bucket.OpenDownloadStreamByName(key)
+
+	if err == gridfs.ErrFileNotFound {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		down.SetReadDeadline(deadline)
+	}
+
+	return down, nil
+}
+
+// Delete - every file named key with its chunks
+func (g *GridFS) Delete(ctx context.Context, key string) error {
+	return g.deleteWhere(ctx, bson.D{{Key: "filename", Value: key}})
+}
+
+// DeletePrefix -
+func (g *GridFS) DeletePrefix(ctx context.Context, prefix string) error {
+	return g.deleteWhere(ctx, bson.D{{Key: "filename", Value: primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}}})
+}
+
+func (g *GridFS) deleteWhere(ctx context.Context, filter bson.D) error {
+
+	cur, err := g.bucket.Find(filter)
+
+	if err != nil {
+		return err
+	}
+
+	var files []struct {
+		ID interface{} `bson:"_id"`
+	}
+
+	if err := cur.All(ctx, &files); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+
+		if err := g.bucket.Delete(f.ID); err != nil && err != gridfs.ErrFileNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
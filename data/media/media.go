@@ -0,0 +1,39 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+)
+
+// ErrNotFound - no image under the key
+var ErrNotFound = errors.New("image not found")
+
+// Store - where blog images live, addressed by keys such as "team-a/5f2011c0.jpg".
+// The blog documents hold the keys.
+type Store interface {
+	// Put - stores everything read from r under key and returns the size.
+	// When r fails nothing is left behind.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+
+	// Open - reads the image under key, ErrNotFound when there is none
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete - removes the image under key, a missing image is not an error
+	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix - removes every image whose key starts with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// ValidKey - keys are relative slash separated paths without . or .. elements
+func ValidKey(key string) bool {
+
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+
+	return path.Clean(key) == key && key != "." && !strings.HasPrefix(key, "../") && key != ".."
+}
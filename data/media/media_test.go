@@ -0,0 +1,151 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestValidKey(t *testing.T) {
+
+	for key, valid := range map[string]bool{
+		"a.jpg":         true,
+		"team-a/b.png":  true,
+		"":              false,
+		"/etc/passwd":   false,
+		"../a.jpg":      false,
+		"team-a/../../": false,
+		"a//b.jpg":      false,
+		"./a.jpg":       false,
+	} {
+
+		if ValidKey(key) != valid {
+			t.Errorf("%q : expected valid %v", key, valid)
+		}
+	}
+}
+
+func TestDisk(t *testing.T) {
+	testStore(t, NewDisk(t.TempDir()))
+}
+
+// TestGridFS - the same checks against a local mongo, skipped without one
+func TestGridFS(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+
+	if err == nil {
+		err = client.Ping(ctx, nil)
+	}
+
+	if err != nil {
+		t.Skipf("mongodb is not reachable : %v", err)
+	}
+
+	db := client.Database("grpcourse_media_test")
+
+	defer func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	}()
+
+	g, err := NewGridFS(db, "images")
+
+	if err != nil {
+		t.Fatalf("cannot open bucket : %v", err)
+	}
+
+	testStore(t, g)
+}
+
+// failing - fails after the first read, like a cancelled upload
+type failing struct{ done bool }
+
+func (f *failing) Read(p []byte) (int, error) {
+
+	if f.done {
+		return 0, errors.New("client went away")
+	}
+
+	f.done = true
+
+	return copy(p, "partial"), nil
+}
+
+func testStore(t *testing.T, s Store) {
+
+	ctx := context.Background()
+
+	image := bytes.Repeat([]byte("jpeg"), 100<<10)
+
+	// 1. round trip
+	if n, err := s.Put(ctx, "red/cover.jpg", bytes.NewReader(image)); err != nil || n != int64(len(image)) {
+		t.Fatalf("cannot put image (%d bytes) : %v", n, err)
+	}
+
+	if got := read(t, s, "red/cover.jpg"); !bytes.Equal(got, image) {
+		t.Errorf("read %d bytes, expected %d", len(got), len(image))
+	}
+
+	// 2. a failed upload leaves nothing behind
+	if _, err := s.Put(ctx, "red/partial.jpg", &failing{}); err == nil {
+		t.Errorf("expected the failed upload to fail")
+	}
+
+	if _, err := s.Open(ctx, "red/partial.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected no partial image, got %v", err)
+	}
+
+	// 3. deletes
+	if _, err := s.Put(ctx, "blue/cover.jpg", bytes.NewReader(image[:10])); err != nil {
+		t.Fatalf("cannot put image : %v", err)
+	}
+
+	if err := s.Delete(ctx, "red/cover.jpg"); err != nil {
+		t.Errorf("cannot delete : %v", err)
+	}
+
+	if err := s.Delete(ctx, "red/cover.jpg"); err != nil {
+		t.Errorf("deleting a missing image should succeed : %v", err)
+	}
+
+	if _, err := s.Open(ctx, "red/cover.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.DeletePrefix(ctx, "blue/"); err != nil {
+		t.Errorf("cannot delete prefix : %v", err)
+	}
+
+	if _, err := s.Open(ctx, "blue/cover.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after DeletePrefix, got %v", err)
+	}
+}
+
+func read(t *testing.T, s Store, key string) []byte {
+
+	r, err := s.Open(context.Background(), key)
+
+	if err != nil {
+		t.Fatalf("cannot open %v : %v", key, err)
+	}
+
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("cannot read %v : %v", key, err)
+	}
+
+	return data
+}
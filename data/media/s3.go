@@ -0,0 +1,328 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// firstRead - buffer a Put starts with, grown up to the part size as the
+// upload turns out bigger. Most images never need a whole part.
+const firstRead = 64 << 10
+
+// abortTimeout - how long cleaning up a failed multipart upload may take
+const abortTimeout = 30 * time.Second
+
+// S3 - images as objects of an S3 compatible bucket, named prefix + key.
+// Uploads up to partSize go in a single request, larger ones in parts of
+// partSize so memory stays bounded. The SDK sends a Content-MD5 with every
+// request; with checksums on the server also verifies and keeps a SHA-256.
+type S3 struct {
+	client   s3iface.S3API
+	bucket   string
+	prefix   string
+	partSize int
+
+	// Checksums - send x-amz-checksum-sha256, off for stores that predate it
+	Checksums bool
+}
+
+// NewS3 - returns a Store on bucket. partSize must be at least 5 MiB for AWS.
+func NewS3(client s3iface.S3API, bucket, prefix string, partSize int) *S3 {
+	return &S3{client: client, bucket: bucket, prefix: prefix, partSize: partSize, Checksums: true}
+}
+
+func (s *S3) object(key string) (*string, error) {
+
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("invalid image key %q", key)
+	}
+
+	return aws.String(s.prefix + key), nil
+}
+
+// sha256 - algorithm and base64 digest of data, nil when checksums are off
+func (s *S3) sha256(data []byte) (*string, *string) {
+
+	if !s.Checksums {
+		return nil, nil
+	}
+
+	sum := sha256.Sum256(data)
+
+	return aws.String(s3.ChecksumAlgorithmSha256), aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// Put - a single PutObject when r ends within the first part, a multipart
+// upload otherwise. A failed multipart upload is aborted.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+
+	object, err := s.object(key)
+
+	if err != nil {
+		return 0, err
+	}
+
+	buf, err := readPart(r, s.partSize)
+
+	switch err {
+	case nil:
+		return s.multipart(ctx, object, buf, r)
+
+	case io.EOF:
+	default:
+		return 0, err
+	}
+
+	n := len(buf)
+
+	in := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         object,
+		Body:        bytes.NewReader(buf[:n]),
+		ContentType: contentType(key),
+	}
+
+	in.ChecksumAlgorithm, in.ChecksumSHA256 = s.sha256(buf[:n])
+
+	if _, err := s.client.PutObjectWithContext(ctx, in); err != nil {
+		return 0, err
+	}
+
+	return int64(n), nil
+}
+
+// readPart - up to size bytes of r. io.EOF when r ended before size bytes,
+// which may then be fewer.
+func readPart(r io.Reader, size int) ([]byte, error) {
+
+	buf := make([]byte, 0, min(firstRead, size))
+
+	for len(buf) < size {
+
+		if len(buf) == cap(buf) {
+			grown := make([]byte, len(buf), min(2*cap(buf), size))
+			copy(grown, buf)
+			buf = grown
+		}
+
+		n, err := r.Read(buf[len(buf):cap(buf)])
+
+		buf = buf[:len(buf)+n]
+
+		if err == io.EOF {
+			return buf, io.EOF
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+func min(a, b int) int {
+
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// multipart - uploads buf, already full, then the rest of r part by part
+func (s *S3) multipart(ctx context.Context, object *string, buf []byte, r io.Reader) (int64, error) {
+
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         object,
+		ContentType: contentType(*object),
+	}
+
+	if s.Checksums {
+		create.ChecksumAlgorithm = aws.String(s3.ChecksumAlgorithmSha256)
+	}
+
+	up, err := s.client.CreateMultipartUploadWithContext(ctx, create)
+
+	if err != nil {
+		return 0, err
+	}
+
+	size, parts, err := s.uploadParts(ctx, object, up.UploadId, buf, r)
+
+	if err == nil {
+
+		_, err = s.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             object,
+			UploadId:        up.UploadId,
+			MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+
+	if err != nil {
+
+		// stored parts are kept, and billed, until the upload is aborted
+		actx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+		defer cancel()
+
+		s.client.AbortMultipartUploadWithContext(actx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      object,
+			UploadId: up.UploadId,
+		})
+
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (s *S3) uploadParts(ctx context.Context, object, id *string, buf []byte, r io.Reader) (int64, []*s3.CompletedPart, error) {
+
+	var (
+		size  int64
+		parts []*s3.CompletedPart
+		n     = len(buf)
+		err   error
+	)
+
+	for number := int64(1); n > 0; number++ {
+
+		in := &s3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        object,
+			UploadId:   id,
+			PartNumber: aws.Int64(number),
+			Body:       bytes.NewReader(buf[:n]),
+		}
+
+		in.ChecksumAlgorithm, in.ChecksumSHA256 = s.sha256(buf[:n])
+
+		out, uerr := s.client.UploadPartWithContext(ctx, in)
+
+		if uerr != nil {
+			return 0, nil, uerr
+		}
+
+		parts = append(parts, &s3.CompletedPart{ETag: out.ETag, PartNumber: in.PartNumber, ChecksumSHA256: in.ChecksumSHA256})
+		size += int64(n)
+
+		// a short read was the last part
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+
+		n, err = io.ReadFull(r, buf)
+
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, nil, err
+		}
+	}
+
+	return size, parts, nil
+}
+
+// Open -
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+
+	object, err := s.object(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: aws.String(s.bucket), Key: object})
+
+	if e, ok := err.(awserr.Error); ok && e.Code() == s3.ErrCodeNoSuchKey {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return out.Body, nil
+}
+
+// Delete - S3 does not fail on missing objects
+func (s *S3) Delete(ctx context.Context, key string) error {
+
+	object, err := s.object(key)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucket), Key: object})
+
+	return err
+}
+
+// DeletePrefix - prefix must name a "directory" eg. "team-a/". Objects are
+// deleted a listing page (up to 1000) at a time.
+func (s *S3) DeletePrefix(ctx context.Context, prefix string) error {
+
+	if !ValidKey(path.Clean(prefix)) {
+		return fmt.Errorf("invalid image prefix %q", prefix)
+	}
+
+	var failed error
+
+	err := s.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix + prefix),
+	}, func(page *s3.ListObjectsV2Output, last bool) bool {
+
+		if len(page.Contents) == 0 {
+			return true
+		}
+
+		var ids []*s3.ObjectIdentifier
+
+		for _, o := range page.Contents {
+			ids = append(ids, &s3.ObjectIdentifier{Key: o.Key})
+		}
+
+		out, err := s.client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucket),
+			Delete: &s3.Delete{Objects: ids, Quiet: aws.Bool(true)},
+		})
+
+		if err == nil && len(out.Errors) > 0 {
+			err = fmt.Errorf("cannot delete %v : %v", aws.StringValue(out.Errors[0].Key), aws.StringValue(out.Errors[0].Message))
+		}
+
+		failed = err
+
+		return err == nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return failed
+}
+
+func contentType(key string) *string {
+
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return aws.String(t)
+	}
+
+	return nil
+}
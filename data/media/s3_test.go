@@ -0,0 +1,170 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+// checksums - in front of the fake, rejects bodies not matching their
+// x-amz-checksum-sha256 like S3 does and counts the verified ones
+type checksums struct {
+	next http.Handler
+
+	mu       sync.Mutex
+	verified int
+}
+
+func (c *checksums) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if want := r.Header.Get("x-amz-checksum-sha256"); want != "" && r.Method == http.MethodPut {
+
+		body, _ := ioutil.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+
+		if base64.StdEncoding.EncodeToString(sum[:]) != want {
+			http.Error(w, "<Error><Code>BadDigest</Code></Error>", http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		c.verified++
+		c.mu.Unlock()
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	c.next.ServeHTTP(w, r)
+}
+
+func fakeS3(t *testing.T) (*s3.S3, *checksums) {
+
+	backend := s3mem.New()
+
+	if err := backend.CreateBucket("images"); err != nil {
+		t.Fatalf("cannot create bucket : %v", err)
+	}
+
+	c := &checksums{next: gofakes3.New(backend).Server()}
+
+	srv := httptest.NewServer(c)
+	t.Cleanup(srv.Close)
+
+	sess, err := session.NewSession(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+
+	if err != nil {
+		t.Fatalf("cannot create session : %v", err)
+	}
+
+	return s3.New(sess), c
+}
+
+func TestS3(t *testing.T) {
+
+	client, sums := fakeS3(t)
+
+	// small parts so the 400KiB test image goes in several
+	testStore(t, NewS3(client, "images", "blog/", 64<<10))
+
+	if sums.verified == 0 {
+		t.Errorf("expected uploads to carry checksums")
+	}
+
+	// every object lives under the prefix
+	out, err := client.ListObjectsV2(&s3.ListObjectsV2Input{Bucket: aws.String("images")})
+
+	if err != nil {
+		t.Fatalf("cannot list : %v", err)
+	}
+
+	for _, o := range out.Contents {
+
+		if !strings.HasPrefix(aws.StringValue(o.Key), "blog/") {
+			t.Errorf("object %v outside the prefix", aws.StringValue(o.Key))
+		}
+	}
+}
+
+func TestS3Multipart(t *testing.T) {
+
+	client, _ := fakeS3(t)
+
+	s := NewS3(client, "images", "", 64<<10)
+
+	// one, exactly one and a bit more than one part
+	for _, size := range []int{10, 64 << 10, 64<<10 + 1, 200 << 10} {
+
+		image := bytes.Repeat([]byte{byte(size)}, size)
+
+		if n, err := s.Put(context.Background(), "a.png", bytes.NewReader(image)); err != nil || n != int64(size) {
+			t.Fatalf("%d bytes : put %d, %v", size, n, err)
+		}
+
+		if got := read(t, s, "a.png"); !bytes.Equal(got, image) {
+			t.Errorf("%d bytes : read back %d", size, len(got))
+		}
+	}
+
+	// a client going away after the first parts aborts the upload
+	broken := io.MultiReader(bytes.NewReader(make([]byte, 100<<10)), &failing{done: true})
+
+	if _, err := s.Put(context.Background(), "b.png", broken); err == nil {
+		t.Errorf("expected the broken upload to fail")
+	}
+
+	out, err := client.ListMultipartUploads(&s3.ListMultipartUploadsInput{Bucket: aws.String("images")})
+
+	if err != nil {
+		t.Fatalf("cannot list uploads : %v", err)
+	}
+
+	if len(out.Uploads) > 0 {
+		t.Errorf("expected no pending uploads, got %d", len(out.Uploads))
+	}
+}
+
+func TestReadPart(t *testing.T) {
+
+	const part = 1 << 20
+
+	for _, size := range []int{0, 10, 200 << 10, part, part + 1} {
+
+		data := bytes.Repeat([]byte{byte(size)}, size)
+
+		buf, err := readPart(bytes.NewReader(data), part)
+
+		// a full part means a multipart upload
+		if full := size >= part; (err == nil) != full || (err != nil && err != io.EOF) {
+			t.Errorf("%d bytes : unexpected error %v", size, err)
+		}
+
+		if want := data[:min(size, part)]; !bytes.Equal(buf, want) {
+			t.Errorf("%d bytes : read %d", size, len(buf))
+		}
+
+		// small uploads do not pay for a whole part
+		if size < part/2 && cap(buf) > firstRead && cap(buf) > 2*size {
+			t.Errorf("%d bytes : buffer of %d", size, cap(buf))
+		}
+	}
+}
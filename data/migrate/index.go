@@ -0,0 +1,174 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index - an index the code relies on
+type Index struct {
+	Collection string
+	Name       string
+	Keys       bson.D
+	Unique     bool
+}
+
+// existing - an index as listed by mongo
+type existing struct {
+	Name   string `bson:"name"`
+	Key    bson.D `bson:"key"`
+	Unique bool   `bson:"unique"`
+}
+
+// Reconcile - makes the indexes of the collections named in indexes match
+// them: missing ones are created, changed ones rebuilt and undeclared ones
+// dropped. Collections without declared indexes are left alone.
+func Reconcile(ctx context.Context, db *mongo.Database, indexes []Index) (created, dropped []string, err error) {
+	return apply(ctx, db, indexes, true)
+}
+
+// Ensure - creates the declared indexes that are missing and drops nothing,
+// safe on every start: indexes added by hand stay, and binaries of two
+// versions running side by side do not undo each other. An index declared
+// under a name that exists with other keys is reported in stale, the
+// migrate command rebuilds it.
+func Ensure(ctx context.Context, db *mongo.Database, indexes []Index) (created, stale []string, err error) {
+	return apply(ctx, db, indexes, false)
+}
+
+// apply - Reconcile when prune is set, Ensure otherwise
+func apply(ctx context.Context, db *mongo.Database, indexes []Index, prune bool) (created, dropped []string, err error) {
+
+	byCollection := map[string][]Index{}
+
+	for _, ix := range indexes {
+		byCollection[ix.Collection] = append(byCollection[ix.Collection], ix)
+	}
+
+	for coll, want := range byCollection {
+
+		c, d, err := reconcile(ctx, db.Collection(coll), want, prune)
+
+		created, dropped = append(created, c...), append(dropped, d...)
+
+		if err != nil {
+			return created, dropped, fmt.Errorf("cannot reconcile indexes of %v : %v", coll, err)
+		}
+	}
+
+	return created, dropped, nil
+}
+
+// reconcile - without prune nothing is dropped, changed indexes are
+// returned in dropped instead
+func reconcile(ctx context.Context, coll *mongo.Collection, want []Index, prune bool) (created, dropped []string, err error) {
+
+	cur, err := coll.Indexes().List(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var have []existing
+
+	if err := cur.All(ctx, &have); err != nil {
+		return nil, nil, err
+	}
+
+	declared := map[string]Index{}
+
+	for _, ix := range want {
+		declared[ix.Name] = ix
+	}
+
+	present := map[string]bool{}
+
+	// 1. drop what is not declared or no longer matches
+	for _, h := range have {
+
+		if h.Name == "_id_" {
+			continue
+		}
+
+		ix, ok := declared[h.Name]
+
+		if ok && ix.Unique == h.Unique && sameKeys(ix.Keys, h.Key) {
+			present[h.Name] = true
+			continue
+		}
+
+		if !prune {
+
+			// the name is taken, creating it again would fail
+			if ok {
+				present[h.Name] = true
+				dropped = append(dropped, coll.Name()+"."+h.Name)
+			}
+
+			continue
+		}
+
+		if _, err := coll.Indexes().DropOne(ctx, h.Name); err != nil {
+			return created, dropped, err
+		}
+
+		dropped = append(dropped, coll.Name()+"."+h.Name)
+	}
+
+	// 2. create what is missing
+	for _, ix := range want {
+
+		if present[ix.Name] {
+			continue
+		}
+
+		model := mongo.IndexModel{Keys: ix.Keys, Options: options.Index().SetName(ix.Name).SetUnique(ix.Unique)}
+
+		if _, err := coll.Indexes().CreateOne(ctx, model); err != nil {
+			return created, dropped, err
+		}
+
+		created = append(created, coll.Name()+"."+ix.Name)
+	}
+
+	return created, dropped, nil
+}
+
+// sameKeys - same fields in the same order and direction. Mongo may list
+// directions as int32 or double.
+func sameKeys(a, b bson.D) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+
+		if a[i].Key != b[i].Key || direction(a[i].Value) != direction(b[i].Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func direction(v interface{}) interface{} {
+
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+
+	// "text", "2dsphere" ...
+	return v
+}
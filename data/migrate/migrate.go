@@ -0,0 +1,205 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// collection - one document per applied migration
+const collection = "schema_migrations"
+
+// Migration - a schema change. Versions are applied in ascending order,
+// Down undoes Up and may be nil for changes that cannot be reverted.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+// record - what schema_migrations holds
+type record struct {
+	Version int       `bson:"_id"`
+	Name    string    `bson:"name"`
+	Applied time.Time `bson:"applied"`
+}
+
+// State - a migration and when it was applied, nil while pending
+type State struct {
+	Migration
+	Applied *time.Time
+}
+
+// Status - every migration of list with its state in db
+func Status(ctx context.Context, db *mongo.Database, list []Migration) ([]State, error) {
+
+	if err := check(list); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedIn(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]State, 0, len(list))
+
+	for _, m := range list {
+
+		st := State{Migration: m}
+
+		if r, ok := applied[m.Version]; ok {
+			st.Applied = &r.Applied
+		}
+
+		states = append(states, st)
+	}
+
+	return states, nil
+}
+
+// Up - applies the pending migrations up to and including version to, 0 for
+// all of them, and returns those applied. It stops at the first failure.
+func Up(ctx context.Context, db *mongo.Database, list []Migration, to int) ([]Migration, error) {
+
+	states, err := Status(ctx, db, list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for _, st := range states {
+
+		if st.Applied != nil {
+			continue
+		}
+
+		if to > 0 && st.Version > to {
+			break
+		}
+
+		if err := st.Up(ctx, db); err != nil {
+			return done, fmt.Errorf("migration %d %v failed : %v", st.Version, st.Name, err)
+		}
+
+		r := record{Version: st.Version, Name: st.Name, Applied: time.Now().UTC()}
+
+		if _, err := db.Collection(collection).InsertOne(ctx, r); err != nil {
+			return done, fmt.Errorf("migration %d %v applied but not recorded : %v", st.Version, st.Name, err)
+		}
+
+		done = append(done, st.Migration)
+	}
+
+	return done, nil
+}
+
+// Down - reverts the last steps applied migrations, newest first, and returns those reverted
+func Down(ctx context.Context, db *mongo.Database, list []Migration, steps int) ([]Migration, error) {
+
+	states, err := Status(ctx, db, list)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for i := len(states) - 1; i >= 0 && len(done) < steps; i-- {
+
+		st := states[i]
+
+		if st.Applied == nil {
+			continue
+		}
+
+		if st.Down == nil {
+			return done, fmt.Errorf("migration %d %v cannot be reverted", st.Version, st.Name)
+		}
+
+		if err := st.Down(ctx, db); err != nil {
+			return done, fmt.Errorf("reverting migration %d %v failed : %v", st.Version, st.Name, err)
+		}
+
+		if _, err := db.Collection(collection).DeleteOne(ctx, bson.D{{Key: "_id", Value: st.Version}}); err != nil {
+			return done, fmt.Errorf("migration %d %v reverted but still recorded : %v", st.Version, st.Name, err)
+		}
+
+		done = append(done, st.Migration)
+	}
+
+	return done, nil
+}
+
+// Pending - number of migrations of list not applied in db
+func Pending(ctx context.Context, db *mongo.Database, list []Migration) (int, error) {
+
+	states, err := Status(ctx, db, list)
+
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+
+	for _, st := range states {
+
+		if st.Applied == nil {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func appliedIn(ctx context.Context, db *mongo.Database) (map[int]record, error) {
+
+	cur, err := db.Collection(collection).Find(ctx, bson.D{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var records []record
+
+	if err := cur.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]record, len(records))
+
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+
+	return applied, nil
+}
+
+// check - versions must be positive, unique and in ascending order
+func check(list []Migration) error {
+
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Version < list[j].Version }) {
+		return fmt.Errorf("migrations are not in version order")
+	}
+
+	for i, m := range list {
+
+		if m.Version <= 0 || m.Up == nil {
+			return fmt.Errorf("migration %d %v needs a positive version and an Up func", m.Version, m.Name)
+		}
+
+		if i > 0 && list[i-1].Version == m.Version {
+			return fmt.Errorf("migration version %d is used twice", m.Version)
+		}
+	}
+
+	return nil
+}
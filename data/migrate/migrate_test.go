@@ -0,0 +1,103 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestCheck(t *testing.T) {
+
+	up := func(context.Context, *mongo.Database) error { return nil }
+
+	if err := check(Migrations); err != nil {
+		t.Errorf("declared migrations rejected : %v", err)
+	}
+
+	for name, list := range map[string][]Migration{
+		"out of order": {{Version: 2, Up: up}, {Version: 1, Up: up}},
+		"duplicate":    {{Version: 1, Up: up}, {Version: 1, Up: up}},
+		"zero version": {{Version: 0, Up: up}},
+		"no up":        {{Version: 1}},
+	} {
+
+		if err := check(list); err == nil {
+			t.Errorf("%v : expected an error", name)
+		}
+	}
+}
+
+func TestSameKeys(t *testing.T) {
+
+	declared := bson.D{{Key: "actor", Value: 1}, {Key: "_id", Value: -1}}
+
+	if !sameKeys(declared, bson.D{{Key: "actor", Value: int32(1)}, {Key: "_id", Value: float64(-1)}}) {
+		t.Errorf("numeric types should not matter")
+	}
+
+	if sameKeys(declared, bson.D{{Key: "actor", Value: 1}, {Key: "_id", Value: 1}}) {
+		t.Errorf("direction should matter")
+	}
+
+	if sameKeys(declared, bson.D{{Key: "_id", Value: -1}, {Key: "actor", Value: 1}}) {
+		t.Errorf("order should matter")
+	}
+}
+
+func TestEnsureKeepsIndexes(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+
+	if err == nil {
+		err = client.Ping(ctx, nil)
+	}
+
+	if err != nil {
+		t.Skipf("mongodb is not reachable : %v", err)
+	}
+
+	db := client.Database("grpcourse_index_test")
+
+	defer func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	}()
+
+	ctx = context.Background()
+
+	coll := db.Collection("blogs")
+
+	// added by hand, or by another version of the server
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "title", Value: 1}}, Options: options.Index().SetName("by_hand")}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Index{{Collection: "blogs", Name: "author", Keys: bson.D{{Key: "author_id", Value: 1}}}}
+
+	created, stale, err := Ensure(ctx, db, want)
+
+	if err != nil || len(created) != 1 || len(stale) != 0 {
+		t.Fatalf("unexpected ensure %v %v %v", created, stale, err)
+	}
+
+	// same name, other keys - reported, not rebuilt
+	changed := []Index{{Collection: "blogs", Name: "author", Keys: bson.D{{Key: "author_id", Value: -1}}}}
+
+	if created, stale, err := Ensure(ctx, db, changed); err != nil || len(created) != 0 || len(stale) != 1 {
+		t.Fatalf("unexpected ensure of a changed index %v %v %v", created, stale, err)
+	}
+
+	// the migrate command still cleans up
+	_, dropped, err := Reconcile(ctx, db, want)
+
+	if err != nil || len(dropped) != 1 || dropped[0] != "blogs.by_hand" {
+		t.Errorf("expected the undeclared index to be dropped, got %v %v", dropped, err)
+	}
+}
@@ -0,0 +1,91 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations - the schema history of a blog database, append new versions at the end
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "rename blog.image to cover_image",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return renameField(ctx, db.Collection("blog"), "image", "cover_image")
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return renameField(ctx, db.Collection("blog"), "cover_image", "image")
+		},
+	},
+	{
+		// images are addressed by keys relative to images.dir, not by paths
+		Version: 2,
+		Name:    "make blog.cover_image an image key",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return rewriteCovers(ctx, db.Collection("blog"), func(v string) string { return strings.TrimPrefix(v, legacyImageDir) })
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return rewriteCovers(ctx, db.Collection("blog"), func(v string) string { return legacyImageDir + v })
+		},
+	},
+}
+
+// legacyImageDir - where images were written before image keys
+const legacyImageDir = "data/images/"
+
+// Indexes - what the queries of the blog and audit services need
+var Indexes = []Index{
+	// ownership checks and listing by author
+	{Collection: "blog", Name: "author_id_1", Keys: bson.D{{Key: "author_id", Value: 1}}},
+
+	// ListAuditEvents pages by _id, newest first, within these filters
+	{Collection: "audit_events", Name: "actor_1__id_-1", Keys: bson.D{{Key: "actor", Value: 1}, {Key: "_id", Value: -1}}},
+	{Collection: "audit_events", Name: "blog_id_1__id_-1", Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "_id", Value: -1}}},
+	{Collection: "audit_events", Name: "time_-1", Keys: bson.D{{Key: "time", Value: -1}}},
+}
+
+// rewriteCovers - sets cover_image to fn(cover_image) on every blog that has one
+func rewriteCovers(ctx context.Context, coll *mongo.Collection, fn func(string) string) error {
+
+	cur, err := coll.Find(ctx, bson.D{{Key: "cover_image", Value: bson.D{{Key: "$gt", Value: ""}}}})
+
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+
+		var doc struct {
+			ID    interface{} `bson:"_id"`
+			Cover string      `bson:"cover_image"`
+		}
+
+		if err := cur.Decode(&doc); err != nil {
+			return err
+		}
+
+		if next := fn(doc.Cover); next != doc.Cover {
+
+			if _, err := coll.UpdateOne(ctx, bson.D{{Key: "_id", Value: doc.ID}}, bson.D{{Key: "$set", Value: bson.D{{Key: "cover_image", Value: next}}}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return cur.Err()
+}
+
+func renameField(ctx context.Context, coll *mongo.Collection, from, to string) error {
+
+	_, err := coll.UpdateMany(ctx,
+		bson.D{{Key: from, Value: bson.D{{Key: "$exists", Value: true}}}},
+		bson.D{{Key: "$rename", Value: bson.D{{Key: from, Value: to}}}},
+	)
+
+	return err
+}
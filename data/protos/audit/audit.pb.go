@@ -0,0 +1,413 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: audit.proto
+
+package auditpb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// AuditEvent - one mutation of a blog, successful or not
+type AuditEvent struct {
+	Id                   string               `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Time                 *timestamp.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Action               string               `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	BlogId               string               `protobuf:"bytes,4,opt,name=blog_id,json=blogId,proto3" json:"blog_id,omitempty"`
+	Actor                string               `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	Certificate          string               `protobuf:"bytes,6,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	Peer                 string               `protobuf:"bytes,7,opt,name=peer,proto3" json:"peer,omitempty"`
+	RequestId            string               `protobuf:"bytes,8,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Outcome              string               `protobuf:"bytes,9,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	Error                string               `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	Before               map[string]string    `protobuf:"bytes,11,rep,name=before,proto3" json:"before,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	After                map[string]string    `protobuf:"bytes,12,rep,name=after,proto3" json:"after,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5594839dd8e38a1b, []int{0}
+}
+
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AuditEvent.Unmarshal(m, b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+}
+func (m *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(m, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return xxx_messageInfo_AuditEvent.Size(m)
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetBlogId() string {
+	if m != nil {
+		return m.BlogId
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetCertificate() string {
+	if m != nil {
+		return m.Certificate
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetOutcome() string {
+	if m != nil {
+		return m.Outcome
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetBefore() map[string]string {
+	if m != nil {
+		return m.Before
+	}
+	return nil
+}
+
+func (m *AuditEvent) GetAfter() map[string]string {
+	if m != nil {
+		return m.After
+	}
+	return nil
+}
+
+// ListAuditEvents messages - every filter is optional
+type ListAuditEventsRequest struct {
+	Actor                string               `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	BlogId               string               `protobuf:"bytes,2,opt,name=blog_id,json=blogId,proto3" json:"blog_id,omitempty"`
+	Since                *timestamp.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until                *timestamp.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	PageSize             int32                `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken            string               `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *ListAuditEventsRequest) Reset()         { *m = ListAuditEventsRequest{} }
+func (m *ListAuditEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAuditEventsRequest) ProtoMessage()    {}
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5594839dd8e38a1b, []int{1}
+}
+
+func (m *ListAuditEventsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAuditEventsRequest.Unmarshal(m, b)
+}
+func (m *ListAuditEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAuditEventsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListAuditEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAuditEventsRequest.Merge(m, src)
+}
+func (m *ListAuditEventsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListAuditEventsRequest.Size(m)
+}
+func (m *ListAuditEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAuditEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAuditEventsRequest proto.InternalMessageInfo
+
+func (m *ListAuditEventsRequest) GetActor() string {
+	if m != nil {
+		return m.Actor
+	}
+	return ""
+}
+
+func (m *ListAuditEventsRequest) GetBlogId() string {
+	if m != nil {
+		return m.BlogId
+	}
+	return ""
+}
+
+func (m *ListAuditEventsRequest) GetSince() *timestamp.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *ListAuditEventsRequest) GetUntil() *timestamp.Timestamp {
+	if m != nil {
+		return m.Until
+	}
+	return nil
+}
+
+func (m *ListAuditEventsRequest) GetPageSize() int32 {
+	if m != nil {
+		return m.PageSize
+	}
+	return 0
+}
+
+func (m *ListAuditEventsRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
+type ListAuditEventsResponse struct {
+	Events               []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken        string        `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListAuditEventsResponse) Reset()         { *m = ListAuditEventsResponse{} }
+func (m *ListAuditEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAuditEventsResponse) ProtoMessage()    {}
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_5594839dd8e38a1b, []int{2}
+}
+
+func (m *ListAuditEventsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListAuditEventsResponse.Unmarshal(m, b)
+}
+func (m *ListAuditEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListAuditEventsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListAuditEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAuditEventsResponse.Merge(m, src)
+}
+func (m *ListAuditEventsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListAuditEventsResponse.Size(m)
+}
+func (m *ListAuditEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAuditEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAuditEventsResponse proto.InternalMessageInfo
+
+func (m *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func (m *ListAuditEventsResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*AuditEvent)(nil), "AuditEvent")
+	proto.RegisterMapType((map[string]string)(nil), "AuditEvent.AfterEntry")
+	proto.RegisterMapType((map[string]string)(nil), "AuditEvent.BeforeEntry")
+	proto.RegisterType((*ListAuditEventsRequest)(nil), "ListAuditEventsRequest")
+	proto.RegisterType((*ListAuditEventsResponse)(nil), "ListAuditEventsResponse")
+}
+
+func init() {
+	proto.RegisterFile("audit.proto", fileDescriptor_5594839dd8e38a1b)
+}
+
+var fileDescriptor_5594839dd8e38a1b = []byte{
+	// 522 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x53, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0x95, 0x9d, 0x57, 0x7d, 0x5d, 0x68, 0x35, 0x42, 0xcd, 0x28, 0x80, 0x88, 0x82, 0x84, 0xba,
+	0x40, 0x36, 0x84, 0x4d, 0x61, 0xd7, 0x4a, 0x5d, 0x54, 0x62, 0x81, 0xdc, 0xae, 0x58, 0x10, 0x4d,
+	0xec, 0x9b, 0x68, 0xd4, 0x64, 0xc6, 0x8c, 0xc7, 0x11, 0xed, 0x92, 0x5f, 0xe0, 0xd3, 0xf8, 0x05,
+	0x24, 0x36, 0x7c, 0x04, 0x9a, 0x3b, 0x8e, 0x62, 0x5e, 0xaa, 0xd8, 0xf9, 0xdc, 0x73, 0xee, 0x23,
+	0xe7, 0x4c, 0x20, 0x16, 0x75, 0x21, 0x6d, 0x52, 0x1a, 0x6d, 0xf5, 0xe8, 0xd1, 0x52, 0xeb, 0xe5,
+	0x0a, 0x53, 0x51, 0xca, 0x54, 0x28, 0xa5, 0xad, 0xb0, 0x52, 0xab, 0xaa, 0x61, 0x9f, 0x34, 0x2c,
+	0xa1, 0x79, 0xbd, 0x48, 0xad, 0x5c, 0x63, 0x65, 0xc5, 0xba, 0xf4, 0x82, 0xc9, 0x8f, 0x0e, 0xc0,
+	0xa9, 0x1b, 0x77, 0xbe, 0x41, 0x65, 0xd9, 0x7d, 0x08, 0x65, 0xc1, 0x83, 0x71, 0x70, 0x1c, 0x65,
+	0xa1, 0x2c, 0x58, 0x02, 0x5d, 0xd7, 0xc1, 0xc3, 0x71, 0x70, 0x1c, 0x4f, 0x47, 0x89, 0x1f, 0x97,
+	0x6c, 0xc7, 0x25, 0x57, 0xdb, 0x71, 0x19, 0xe9, 0xd8, 0x11, 0xf4, 0x45, 0xee, 0x0e, 0xe0, 0x1d,
+	0x9a, 0xd1, 0x20, 0x36, 0x84, 0xc1, 0x7c, 0xa5, 0x97, 0x33, 0x59, 0xf0, 0xae, 0x27, 0x1c, 0xbc,
+	0x28, 0xd8, 0x03, 0xe8, 0x89, 0xdc, 0x6a, 0xc3, 0x7b, 0x54, 0xf6, 0x80, 0x8d, 0x21, 0xce, 0xd1,
+	0x58, 0xb9, 0x90, 0xb9, 0xb0, 0xc8, 0xfb, 0xc4, 0xb5, 0x4b, 0x8c, 0x41, 0xb7, 0x44, 0x34, 0x7c,
+	0x40, 0x14, 0x7d, 0xb3, 0xc7, 0x00, 0x06, 0x3f, 0xd6, 0x58, 0x59, 0xb7, 0x67, 0x8f, 0x98, 0xa8,
+	0xa9, 0x5c, 0x14, 0x8c, 0xc3, 0x40, 0xd7, 0x36, 0xd7, 0x6b, 0xe4, 0x11, 0x71, 0x5b, 0xe8, 0x8e,
+	0x40, 0x63, 0xb4, 0xe1, 0xe0, 0x8f, 0x20, 0xc0, 0x52, 0xe8, 0xcf, 0x71, 0xa1, 0x0d, 0xf2, 0x78,
+	0xdc, 0x39, 0x8e, 0xa7, 0xc3, 0x64, 0x67, 0x54, 0x72, 0x46, 0xcc, 0xb9, 0xb2, 0xe6, 0x26, 0x6b,
+	0x64, 0xec, 0x39, 0xf4, 0xc4, 0xc2, 0xa2, 0xe1, 0xfb, 0xa4, 0x3f, 0x6a, 0xeb, 0x4f, 0x1d, 0xe1,
+	0xe5, 0x5e, 0x34, 0x7a, 0x0d, 0x71, 0x6b, 0x08, 0x3b, 0x84, 0xce, 0x35, 0xde, 0x34, 0xd6, 0xbb,
+	0x4f, 0x77, 0xd5, 0x46, 0xac, 0x6a, 0x6f, 0x7e, 0x94, 0x79, 0xf0, 0x26, 0x3c, 0x09, 0x46, 0x27,
+	0x00, 0xbb, 0x79, 0xff, 0xd3, 0x39, 0xf9, 0x1e, 0xc0, 0xd1, 0x5b, 0x59, 0xd9, 0xdd, 0x65, 0x55,
+	0xe6, 0x0d, 0xda, 0x25, 0x11, 0xb4, 0x93, 0x68, 0x05, 0x17, 0xfe, 0x12, 0xdc, 0x0b, 0xe8, 0x55,
+	0x52, 0xe5, 0xc8, 0x3b, 0x77, 0x3e, 0x0d, 0x2f, 0x74, 0x1d, 0xb5, 0xb2, 0x72, 0xc5, 0xbb, 0x77,
+	0x77, 0x90, 0x90, 0x3d, 0x84, 0xa8, 0x14, 0x4b, 0x9c, 0x55, 0xf2, 0x16, 0xe9, 0x81, 0xf4, 0xb2,
+	0x3d, 0x57, 0xb8, 0x94, 0xb7, 0xe8, 0xd2, 0x26, 0xd2, 0xea, 0x6b, 0x54, 0xcd, 0x13, 0x21, 0xf9,
+	0x95, 0x2b, 0x4c, 0x16, 0x30, 0xfc, 0xe3, 0x87, 0x56, 0xa5, 0x56, 0x15, 0xb2, 0xa7, 0xd0, 0x47,
+	0xaa, 0xf0, 0x80, 0x82, 0x8a, 0x5b, 0x41, 0x65, 0x0d, 0xc5, 0x9e, 0xc1, 0x81, 0xc2, 0x4f, 0x76,
+	0xd6, 0xda, 0xe1, 0x0d, 0xb8, 0xe7, 0xca, 0xef, 0xb6, 0x7b, 0xa6, 0x0a, 0xf6, 0xa9, 0xfb, 0x12,
+	0xcd, 0x46, 0xe6, 0xc8, 0x3e, 0xc0, 0xc1, 0x6f, 0x7b, 0xd9, 0x30, 0xf9, 0xbb, 0xe5, 0x23, 0x9e,
+	0xfc, 0xe3, 0xc4, 0x09, 0xff, 0xfc, 0xf5, 0xdb, 0x97, 0x90, 0xb1, 0xc3, 0x74, 0xf3, 0x32, 0xa5,
+	0xbf, 0x7b, 0xea, 0xef, 0x3a, 0x8b, 0xde, 0x0f, 0x08, 0x97, 0xf3, 0x79, 0x9f, 0x9c, 0x7b, 0xf5,
+	0x73, 0x00, 0xd0, 0x97, 0x82, 0x3c, 0x10, 0x04, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AuditServiceClient is the client API for AuditService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AuditServiceClient interface {
+	// ListAuditEvents - newest first, admins only
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
+}
+
+type auditServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditServiceClient(cc grpc.ClientConnInterface) AuditServiceClient {
+	return &auditServiceClient{cc}
+}
+
+func (c *auditServiceClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, "/AuditService/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuditServiceServer is the server API for AuditService service.
+type AuditServiceServer interface {
+	// ListAuditEvents - newest first, admins only
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
+}
+
+// UnimplementedAuditServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedAuditServiceServer struct {
+}
+
+func (*UnimplementedAuditServiceServer) ListAuditEvents(ctx context.Context, req *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+
+func RegisterAuditServiceServer(s *grpc.Server, srv AuditServiceServer) {
+	s.RegisterService(&_AuditService_serviceDesc, srv)
+}
+
+func _AuditService_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditServiceServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/AuditService/ListAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditServiceServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _AuditService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "AuditService",
+	HandlerType: (*AuditServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _AuditService_ListAuditEvents_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "audit.proto",
+}
@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -25,14 +26,15 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
 type Blog struct {
-	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	AuthorId             string   `protobuf:"bytes,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
-	Title                string   `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
-	Body                 string   `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
-	ImagePath            string   `protobuf:"bytes,5,opt,name=image_path,json=imagePath,proto3" json:"image_path,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Id                   string          `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AuthorId             string          `protobuf:"bytes,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Title                string          `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Body                 string          `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	ImagePath            string          `protobuf:"bytes,5,opt,name=image_path,json=imagePath,proto3" json:"image_path,omitempty"`
+	ImageVariants        []*ImageVariant `protobuf:"bytes,6,rep,name=image_variants,json=imageVariants,proto3" json:"image_variants,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *Blog) Reset()         { *m = Blog{} }
@@ -95,6 +97,93 @@ func (m *Blog) GetImagePath() string {
 	return ""
 }
 
+func (m *Blog) GetImageVariants() []*ImageVariant {
+	if m != nil {
+		return m.ImageVariants
+	}
+	return nil
+}
+
+// ImageVariant - a rendition of the cover image, upright and without metadata
+type ImageVariant struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Url                  string   `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	ContentType          string   `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Width                int32    `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`
+	Height               int32    `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ImageVariant) Reset()         { *m = ImageVariant{} }
+func (m *ImageVariant) String() string { return proto.CompactTextString(m) }
+func (*ImageVariant) ProtoMessage()    {}
+func (*ImageVariant) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6745b25902462fb1, []int{1}
+}
+
+func (m *ImageVariant) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ImageVariant.Unmarshal(m, b)
+}
+func (m *ImageVariant) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ImageVariant.Marshal(b, m, deterministic)
+}
+func (m *ImageVariant) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ImageVariant.Merge(m, src)
+}
+func (m *ImageVariant) XXX_Size() int {
+	return xxx_messageInfo_ImageVariant.Size(m)
+}
+func (m *ImageVariant) XXX_DiscardUnknown() {
+	xxx_messageInfo_ImageVariant.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ImageVariant proto.InternalMessageInfo
+
+func (m *ImageVariant) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ImageVariant) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *ImageVariant) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *ImageVariant) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *ImageVariant) GetWidth() int32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *ImageVariant) GetHeight() int32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
 // CreateBlog messages
 type CreateBlogRequest struct {
 	// Types that are valid to be assigned to Data:
@@ -110,7 +199,7 @@ func (m *CreateBlogRequest) Reset()         { *m = CreateBlogRequest{} }
 func (m *CreateBlogRequest) String() string { return proto.CompactTextString(m) }
 func (*CreateBlogRequest) ProtoMessage()    {}
 func (*CreateBlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{1}
+	return fileDescriptor_6745b25902462fb1, []int{2}
 }
 
 func (m *CreateBlogRequest) XXX_Unmarshal(b []byte) error {
@@ -187,7 +276,7 @@ func (m *CreateBlogResponse) Reset()         { *m = CreateBlogResponse{} }
 func (m *CreateBlogResponse) String() string { return proto.CompactTextString(m) }
 func (*CreateBlogResponse) ProtoMessage()    {}
 func (*CreateBlogResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{2}
+	return fileDescriptor_6745b25902462fb1, []int{3}
 }
 
 func (m *CreateBlogResponse) XXX_Unmarshal(b []byte) error {
@@ -227,7 +316,7 @@ func (m *ReadBlogRequest) Reset()         { *m = ReadBlogRequest{} }
 func (m *ReadBlogRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadBlogRequest) ProtoMessage()    {}
 func (*ReadBlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{3}
+	return fileDescriptor_6745b25902462fb1, []int{4}
 }
 
 func (m *ReadBlogRequest) XXX_Unmarshal(b []byte) error {
@@ -266,7 +355,7 @@ func (m *ReadBlogResponse) Reset()         { *m = ReadBlogResponse{} }
 func (m *ReadBlogResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadBlogResponse) ProtoMessage()    {}
 func (*ReadBlogResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{4}
+	return fileDescriptor_6745b25902462fb1, []int{5}
 }
 
 func (m *ReadBlogResponse) XXX_Unmarshal(b []byte) error {
@@ -307,7 +396,7 @@ func (m *UpdateBlogRequest) Reset()         { *m = UpdateBlogRequest{} }
 func (m *UpdateBlogRequest) String() string { return proto.CompactTextString(m) }
 func (*UpdateBlogRequest) ProtoMessage()    {}
 func (*UpdateBlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{5}
+	return fileDescriptor_6745b25902462fb1, []int{6}
 }
 
 func (m *UpdateBlogRequest) XXX_Unmarshal(b []byte) error {
@@ -353,7 +442,7 @@ func (m *UpdateBlogResponse) Reset()         { *m = UpdateBlogResponse{} }
 func (m *UpdateBlogResponse) String() string { return proto.CompactTextString(m) }
 func (*UpdateBlogResponse) ProtoMessage()    {}
 func (*UpdateBlogResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{6}
+	return fileDescriptor_6745b25902462fb1, []int{7}
 }
 
 func (m *UpdateBlogResponse) XXX_Unmarshal(b []byte) error {
@@ -393,7 +482,7 @@ func (m *DeleteBlogRequest) Reset()         { *m = DeleteBlogRequest{} }
 func (m *DeleteBlogRequest) String() string { return proto.CompactTextString(m) }
 func (*DeleteBlogRequest) ProtoMessage()    {}
 func (*DeleteBlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{7}
+	return fileDescriptor_6745b25902462fb1, []int{8}
 }
 
 func (m *DeleteBlogRequest) XXX_Unmarshal(b []byte) error {
@@ -432,7 +521,7 @@ func (m *DeleteBlogResponse) Reset()         { *m = DeleteBlogResponse{} }
 func (m *DeleteBlogResponse) String() string { return proto.CompactTextString(m) }
 func (*DeleteBlogResponse) ProtoMessage()    {}
 func (*DeleteBlogResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{8}
+	return fileDescriptor_6745b25902462fb1, []int{9}
 }
 
 func (m *DeleteBlogResponse) XXX_Unmarshal(b []byte) error {
@@ -460,6 +549,101 @@ func (m *DeleteBlogResponse) GetId() string {
 	return ""
 }
 
+// DownloadImage messages
+type DownloadImageRequest struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Variant              string   `protobuf:"bytes,2,opt,name=variant,proto3" json:"variant,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DownloadImageRequest) Reset()         { *m = DownloadImageRequest{} }
+func (m *DownloadImageRequest) String() string { return proto.CompactTextString(m) }
+func (*DownloadImageRequest) ProtoMessage()    {}
+func (*DownloadImageRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6745b25902462fb1, []int{10}
+}
+
+func (m *DownloadImageRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DownloadImageRequest.Unmarshal(m, b)
+}
+func (m *DownloadImageRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DownloadImageRequest.Marshal(b, m, deterministic)
+}
+func (m *DownloadImageRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DownloadImageRequest.Merge(m, src)
+}
+func (m *DownloadImageRequest) XXX_Size() int {
+	return xxx_messageInfo_DownloadImageRequest.Size(m)
+}
+func (m *DownloadImageRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DownloadImageRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DownloadImageRequest proto.InternalMessageInfo
+
+func (m *DownloadImageRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *DownloadImageRequest) GetVariant() string {
+	if m != nil {
+		return m.Variant
+	}
+	return ""
+}
+
+type DownloadImageResponse struct {
+	ContentType          string   `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Chunk                []byte   `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DownloadImageResponse) Reset()         { *m = DownloadImageResponse{} }
+func (m *DownloadImageResponse) String() string { return proto.CompactTextString(m) }
+func (*DownloadImageResponse) ProtoMessage()    {}
+func (*DownloadImageResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6745b25902462fb1, []int{11}
+}
+
+func (m *DownloadImageResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DownloadImageResponse.Unmarshal(m, b)
+}
+func (m *DownloadImageResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DownloadImageResponse.Marshal(b, m, deterministic)
+}
+func (m *DownloadImageResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DownloadImageResponse.Merge(m, src)
+}
+func (m *DownloadImageResponse) XXX_Size() int {
+	return xxx_messageInfo_DownloadImageResponse.Size(m)
+}
+func (m *DownloadImageResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DownloadImageResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DownloadImageResponse proto.InternalMessageInfo
+
+func (m *DownloadImageResponse) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *DownloadImageResponse) GetChunk() []byte {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
 // ListBlog messages
 type ListBlogRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -471,7 +655,7 @@ func (m *ListBlogRequest) Reset()         { *m = ListBlogRequest{} }
 func (m *ListBlogRequest) String() string { return proto.CompactTextString(m) }
 func (*ListBlogRequest) ProtoMessage()    {}
 func (*ListBlogRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{9}
+	return fileDescriptor_6745b25902462fb1, []int{12}
 }
 
 func (m *ListBlogRequest) XXX_Unmarshal(b []byte) error {
@@ -503,7 +687,7 @@ func (m *ListBlogResponse) Reset()         { *m = ListBlogResponse{} }
 func (m *ListBlogResponse) String() string { return proto.CompactTextString(m) }
 func (*ListBlogResponse) ProtoMessage()    {}
 func (*ListBlogResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6745b25902462fb1, []int{10}
+	return fileDescriptor_6745b25902462fb1, []int{13}
 }
 
 func (m *ListBlogResponse) XXX_Unmarshal(b []byte) error {
@@ -533,6 +717,7 @@ func (m *ListBlogResponse) GetBlogs() []*Blog {
 
 func init() {
 	proto.RegisterType((*Blog)(nil), "Blog")
+	proto.RegisterType((*ImageVariant)(nil), "ImageVariant")
 	proto.RegisterType((*CreateBlogRequest)(nil), "CreateBlogRequest")
 	proto.RegisterType((*CreateBlogResponse)(nil), "CreateBlogResponse")
 	proto.RegisterType((*ReadBlogRequest)(nil), "ReadBlogRequest")
@@ -541,6 +726,8 @@ func init() {
 	proto.RegisterType((*UpdateBlogResponse)(nil), "UpdateBlogResponse")
 	proto.RegisterType((*DeleteBlogRequest)(nil), "DeleteBlogRequest")
 	proto.RegisterType((*DeleteBlogResponse)(nil), "DeleteBlogResponse")
+	proto.RegisterType((*DownloadImageRequest)(nil), "DownloadImageRequest")
+	proto.RegisterType((*DownloadImageResponse)(nil), "DownloadImageResponse")
 	proto.RegisterType((*ListBlogRequest)(nil), "ListBlogRequest")
 	proto.RegisterType((*ListBlogResponse)(nil), "ListBlogResponse")
 }
@@ -550,32 +737,48 @@ func init() {
 }
 
 var fileDescriptor_6745b25902462fb1 = []byte{
-	// 385 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x84, 0x53, 0xc1, 0x4a, 0xc3, 0x40,
-	0x10, 0xb5, 0x6d, 0x52, 0xda, 0xa9, 0xd8, 0x66, 0x14, 0x89, 0x2d, 0x82, 0x46, 0x0f, 0xbd, 0x98,
-	0x42, 0x45, 0xbc, 0xd7, 0x1e, 0x14, 0x3c, 0x94, 0x88, 0x17, 0x2f, 0x65, 0x63, 0x96, 0x36, 0x50,
-	0x4d, 0x4c, 0xb6, 0x82, 0xe0, 0x77, 0xf8, 0xbd, 0xce, 0x6e, 0x52, 0x12, 0xb3, 0x95, 0xde, 0x32,
-	0x6f, 0xe7, 0xcd, 0xbc, 0x79, 0x8f, 0x00, 0xf8, 0xab, 0x68, 0xe1, 0xc6, 0x49, 0x24, 0x22, 0xe7,
-	0x1b, 0x8c, 0x09, 0x55, 0x78, 0x00, 0xf5, 0x30, 0xb0, 0x6b, 0x67, 0xb5, 0x61, 0xdb, 0xa3, 0x2f,
-	0x1c, 0x40, 0x9b, 0xad, 0xc5, 0x32, 0x4a, 0xe6, 0x04, 0xd7, 0x15, 0xdc, 0xca, 0x80, 0x87, 0x00,
-	0x8f, 0xc0, 0x14, 0xa1, 0x58, 0x71, 0xbb, 0xa1, 0x1e, 0xb2, 0x02, 0x11, 0x0c, 0x3f, 0x0a, 0xbe,
-	0x6c, 0x43, 0x81, 0xea, 0x1b, 0x4f, 0x01, 0xc2, 0x37, 0xb6, 0xe0, 0xf3, 0x98, 0x89, 0xa5, 0x6d,
-	0xaa, 0x97, 0xb6, 0x42, 0x66, 0x04, 0x38, 0x33, 0xb0, 0xee, 0x12, 0xce, 0x04, 0x97, 0x1a, 0x3c,
-	0xfe, 0xb1, 0xe6, 0xa9, 0xa0, 0xd5, 0x86, 0x14, 0xa8, 0xc4, 0x74, 0xc6, 0xa6, 0x2b, 0xdf, 0xee,
-	0xf7, 0x3c, 0x05, 0xe2, 0x31, 0x98, 0x8a, 0xae, 0x34, 0xed, 0x13, 0x9c, 0x95, 0x93, 0x26, 0x18,
-	0x01, 0x13, 0xcc, 0x19, 0x01, 0x96, 0x27, 0xa6, 0x71, 0xf4, 0x9e, 0x72, 0x3c, 0xd9, 0x32, 0x32,
-	0x1b, 0xe8, 0x9c, 0x43, 0xd7, 0xe3, 0x2c, 0x28, 0x0b, 0xa8, 0x78, 0xe1, 0x5c, 0x41, 0xaf, 0x68,
-	0xd9, 0x3d, 0x71, 0x0a, 0xd6, 0x73, 0x1c, 0x54, 0x8e, 0xfa, 0xbf, 0x5f, 0xba, 0x59, 0x3a, 0x29,
-	0x3f, 0x48, 0x1e, 0x52, 0x9e, 0xb2, 0x7b, 0xed, 0x05, 0x58, 0x53, 0xbe, 0xe2, 0x7f, 0xd7, 0x56,
-	0x4f, 0xb9, 0x04, 0x2c, 0x37, 0xe5, 0x53, 0xab, 0x5d, 0x16, 0x74, 0x1f, 0xc3, 0x54, 0x94, 0x06,
-	0x91, 0x9c, 0x5e, 0x01, 0xe5, 0xb4, 0x01, 0x98, 0x72, 0x73, 0x4a, 0xcc, 0x46, 0xa1, 0x26, 0xc3,
-	0xc6, 0x3f, 0x75, 0xe8, 0xc8, 0xfa, 0x89, 0x27, 0x9f, 0xe1, 0x2b, 0xc7, 0x5b, 0x80, 0x22, 0x18,
-	0x44, 0x57, 0xcb, 0xbd, 0x7f, 0xe8, 0xea, 0xc9, 0x0d, 0x6b, 0x38, 0x82, 0xd6, 0xc6, 0x7d, 0xec,
-	0xb9, 0x95, 0xac, 0xfa, 0x96, 0xab, 0x45, 0x43, 0x84, 0x8d, 0x54, 0x22, 0x54, 0x0e, 0x21, 0x82,
-	0x76, 0xc7, 0x0d, 0x40, 0x61, 0x35, 0x49, 0xd3, 0xd2, 0x23, 0x69, 0x5b, 0xb2, 0x20, 0x5a, 0xe1,
-	0x25, 0xd1, 0x34, 0xf7, 0x89, 0xa6, 0x9b, 0x3d, 0x69, 0xbd, 0x34, 0xa5, 0x43, 0xb1, 0xef, 0x37,
-	0xd5, 0x2f, 0x78, 0xfd, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x1c, 0xe8, 0xc4, 0xae, 0x90, 0x03, 0x00,
-	0x00,
+	// 642 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x94, 0xcd, 0x6e, 0xd3, 0x4e,
+	0x14, 0xc5, 0xeb, 0x7c, 0xfd, 0x93, 0x9b, 0x7e, 0xc4, 0x37, 0x6d, 0xe4, 0xbf, 0x0b, 0xa8, 0x35,
+	0x2c, 0xaa, 0x4a, 0x8c, 0xa1, 0x20, 0x21, 0xb1, 0xaa, 0x42, 0x25, 0x5a, 0x09, 0xa4, 0xca, 0x50,
+	0x16, 0x6c, 0xaa, 0x49, 0x3d, 0x4a, 0x46, 0x75, 0x3d, 0x26, 0x9e, 0xa4, 0x8a, 0x50, 0x37, 0xbc,
+	0x02, 0x0b, 0xde, 0x83, 0x2d, 0x8f, 0xc1, 0x2b, 0xf0, 0x20, 0x68, 0xc6, 0x13, 0x62, 0xec, 0x94,
+	0xae, 0x32, 0xf7, 0xdc, 0xd1, 0x99, 0x7b, 0xae, 0x7f, 0x0a, 0xc0, 0x20, 0x12, 0x43, 0x92, 0x8c,
+	0x85, 0x14, 0xee, 0xbd, 0xa1, 0x10, 0xc3, 0x88, 0xf9, 0x34, 0xe1, 0x3e, 0x8d, 0x63, 0x21, 0xa9,
+	0xe4, 0x22, 0x4e, 0xb3, 0xae, 0xf7, 0xdd, 0x82, 0x5a, 0x3f, 0x12, 0x43, 0x5c, 0x87, 0x0a, 0x0f,
+	0x1d, 0x6b, 0xc7, 0xda, 0x6b, 0x05, 0x15, 0x1e, 0xe2, 0x36, 0xb4, 0xe8, 0x44, 0x8e, 0xc4, 0xf8,
+	0x9c, 0x87, 0x4e, 0x45, 0xcb, 0xcd, 0x4c, 0x38, 0x09, 0x71, 0x13, 0xea, 0x92, 0xcb, 0x88, 0x39,
+	0x55, 0xdd, 0xc8, 0x0a, 0x44, 0xa8, 0x0d, 0x44, 0x38, 0x73, 0x6a, 0x5a, 0xd4, 0x67, 0xbc, 0x0f,
+	0xc0, 0xaf, 0xe8, 0x90, 0x9d, 0x27, 0x54, 0x8e, 0x9c, 0xba, 0xee, 0xb4, 0xb4, 0x72, 0x4a, 0xe5,
+	0x08, 0x9f, 0xc3, 0x7a, 0xd6, 0x9e, 0xd2, 0x31, 0xa7, 0xb1, 0x4c, 0x9d, 0xc6, 0x4e, 0x75, 0xaf,
+	0x7d, 0xb0, 0x46, 0x4e, 0x94, 0xfc, 0x21, 0x53, 0x83, 0x35, 0x9e, 0xab, 0x52, 0xef, 0x9b, 0x05,
+	0xab, 0xf9, 0xbe, 0x7a, 0x39, 0xa6, 0x57, 0xcc, 0x8c, 0xaf, 0xcf, 0xd8, 0x81, 0xea, 0x25, 0x9b,
+	0x99, 0xd1, 0xd5, 0x51, 0x29, 0x93, 0x71, 0x64, 0x66, 0x56, 0x47, 0xdc, 0x85, 0xd5, 0x0b, 0x11,
+	0x4b, 0x16, 0xcb, 0x73, 0x39, 0x4b, 0x98, 0x99, 0xbc, 0x6d, 0xb4, 0xf7, 0xb3, 0x84, 0xa9, 0xa8,
+	0xd7, 0x3c, 0x34, 0xb3, 0xd7, 0x83, 0xac, 0xc0, 0x1e, 0x34, 0x46, 0x8c, 0x0f, 0x47, 0xd2, 0x69,
+	0x68, 0xd9, 0x54, 0xde, 0x29, 0xd8, 0xaf, 0xc6, 0x8c, 0x4a, 0xa6, 0x76, 0x1a, 0xb0, 0x4f, 0x13,
+	0x96, 0x4a, 0xdc, 0x86, 0x9a, 0xfa, 0x1e, 0x7a, 0xba, 0xf6, 0x41, 0x9d, 0xa8, 0xde, 0xf1, 0x4a,
+	0xa0, 0x45, 0xec, 0x41, 0x5d, 0x87, 0xd3, 0x83, 0xae, 0x1e, 0xaf, 0x04, 0x59, 0xd9, 0x6f, 0x40,
+	0x2d, 0xa4, 0x92, 0x7a, 0x3e, 0x60, 0xde, 0x31, 0x4d, 0x44, 0x9c, 0x32, 0xfc, 0x7f, 0x89, 0x65,
+	0x66, 0xe8, 0xed, 0xc2, 0x46, 0xc0, 0x68, 0x98, 0x1f, 0xa0, 0xf0, 0x6d, 0xbd, 0xc7, 0xd0, 0x59,
+	0x5c, 0xb9, 0xdb, 0xf1, 0x08, 0xec, 0xb3, 0x24, 0x2c, 0x84, 0xba, 0xfd, 0xbe, 0x5a, 0x59, 0x2e,
+	0x92, 0x09, 0xa4, 0x82, 0xe4, 0x5d, 0xee, 0x7e, 0xf6, 0x21, 0xd8, 0x47, 0x2c, 0x62, 0x92, 0xfd,
+	0x2b, 0xca, 0x23, 0xc0, 0xfc, 0x25, 0xe3, 0x5a, 0xbc, 0x75, 0x08, 0x9b, 0x47, 0xe2, 0x3a, 0x8e,
+	0x04, 0x0d, 0x35, 0x37, 0xb7, 0xb8, 0xa1, 0x03, 0xff, 0x19, 0x10, 0x0d, 0x37, 0xf3, 0xd2, 0x3b,
+	0x85, 0xad, 0x82, 0x83, 0x79, 0xaa, 0x88, 0x90, 0xb5, 0x14, 0xa1, 0x8b, 0xd1, 0x24, 0xbe, 0x9c,
+	0xef, 0x43, 0x17, 0x9e, 0x0d, 0x1b, 0x6f, 0x78, 0x2a, 0x73, 0xe1, 0x3c, 0x1f, 0x3a, 0x0b, 0xc9,
+	0xf8, 0x6f, 0x43, 0x5d, 0x6d, 0x23, 0x75, 0xac, 0x9d, 0xea, 0x62, 0x43, 0x99, 0x76, 0xf0, 0xa3,
+	0x0a, 0x6d, 0x55, 0xbf, 0x63, 0xe3, 0x29, 0xbf, 0x60, 0xf8, 0x02, 0x60, 0x01, 0x0b, 0x22, 0x29,
+	0xb1, 0xe8, 0x76, 0x49, 0x99, 0xa6, 0x3d, 0x0b, 0x5f, 0x43, 0x73, 0x4e, 0x04, 0x76, 0x48, 0x81,
+	0x1f, 0xd7, 0x26, 0x45, 0x5c, 0xbc, 0xde, 0x97, 0x9f, 0xbf, 0xbe, 0x56, 0x3a, 0xb8, 0xee, 0x4f,
+	0x9f, 0xfa, 0x7a, 0x18, 0xff, 0x33, 0x0f, 0x6f, 0xb0, 0x0f, 0xcd, 0x79, 0x04, 0xec, 0x90, 0x42,
+	0x40, 0xd7, 0x26, 0xc5, 0x7c, 0x9e, 0xad, 0x8d, 0xda, 0xd8, 0xfa, 0x63, 0x84, 0x67, 0x00, 0x0b,
+	0x52, 0x10, 0x49, 0x09, 0x3e, 0xb7, 0x4b, 0xca, 0x28, 0x79, 0x0f, 0xb4, 0x93, 0xe3, 0x76, 0x73,
+	0x23, 0xa9, 0x1f, 0xc2, 0xc3, 0x9b, 0x97, 0xd6, 0x3e, 0xbe, 0x05, 0x58, 0xa0, 0x82, 0x48, 0x4a,
+	0x70, 0xb9, 0x5d, 0x52, 0x66, 0x69, 0x9e, 0x74, 0xbf, 0x98, 0xf4, 0x10, 0xd6, 0xfe, 0x22, 0x02,
+	0xb7, 0xc8, 0x32, 0xc6, 0xdc, 0x1e, 0x59, 0x0a, 0xce, 0x13, 0xab, 0xdf, 0xfc, 0xd8, 0x50, 0x7e,
+	0xc9, 0x60, 0xd0, 0xd0, 0x7f, 0xc6, 0xcf, 0x7e, 0x0f, 0x00, 0xcc, 0x78, 0x6e, 0x2d, 0xb8, 0x05,
+	0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -591,6 +794,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type BlogServiceClient interface {
 	// CreateBlog - inserts a new blog to the db
+	// REST : multipart POST /v1/blogs is served by the gateway itself
 	CreateBlog(ctx context.Context, opts ...grpc.CallOption) (BlogService_CreateBlogClient, error)
 	// ReadBlog - fetches blog record from collection. Return NOT_FOUND if missing
 	ReadBlog(ctx context.Context, in *ReadBlogRequest, opts ...grpc.CallOption) (*ReadBlogResponse, error)
@@ -600,6 +804,9 @@ type BlogServiceClient interface {
 	UpdateBlog(ctx context.Context, in *UpdateBlogRequest, opts ...grpc.CallOption) (*UpdateBlogResponse, error)
 	// DeleteBlog - deletes an existing record of a blog - return id
 	DeleteBlog(ctx context.Context, in *DeleteBlogRequest, opts ...grpc.CallOption) (*DeleteBlogResponse, error)
+	// DownloadImage - streams the cover image of a blog, or one of its variants, in chunks. Return NOT_FOUND if missing
+	// REST : GET /v1/blogs/{id}/image?variant= is served by the gateway as a chunked HTTP response
+	DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (BlogService_DownloadImageClient, error)
 }
 
 type blogServiceClient struct {
@@ -680,9 +887,42 @@ func (c *blogServiceClient) DeleteBlog(ctx context.Context, in *DeleteBlogReques
 	return out, nil
 }
 
+func (c *blogServiceClient) DownloadImage(ctx context.Context, in *DownloadImageRequest, opts ...grpc.CallOption) (BlogService_DownloadImageClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_BlogService_serviceDesc.Streams[1], "/BlogService/DownloadImage", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blogServiceDownloadImageClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlogService_DownloadImageClient interface {
+	Recv() (*DownloadImageResponse, error)
+	grpc.ClientStream
+}
+
+type blogServiceDownloadImageClient struct {
+	grpc.ClientStream
+}
+
+func (x *blogServiceDownloadImageClient) Recv() (*DownloadImageResponse, error) {
+	m := new(DownloadImageResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // BlogServiceServer is the server API for BlogService service.
 type BlogServiceServer interface {
 	// CreateBlog - inserts a new blog to the db
+	// REST : multipart POST /v1/blogs is served by the gateway itself
 	CreateBlog(BlogService_CreateBlogServer) error
 	// ReadBlog - fetches blog record from collection. Return NOT_FOUND if missing
 	ReadBlog(context.Context, *ReadBlogRequest) (*ReadBlogResponse, error)
@@ -692,6 +932,9 @@ type BlogServiceServer interface {
 	UpdateBlog(context.Context, *UpdateBlogRequest) (*UpdateBlogResponse, error)
 	// DeleteBlog - deletes an existing record of a blog - return id
 	DeleteBlog(context.Context, *DeleteBlogRequest) (*DeleteBlogResponse, error)
+	// DownloadImage - streams the cover image of a blog, or one of its variants, in chunks. Return NOT_FOUND if missing
+	// REST : GET /v1/blogs/{id}/image?variant= is served by the gateway as a chunked HTTP response
+	DownloadImage(*DownloadImageRequest, BlogService_DownloadImageServer) error
 }
 
 // UnimplementedBlogServiceServer can be embedded to have forward compatible implementations.
@@ -713,6 +956,9 @@ func (*UnimplementedBlogServiceServer) UpdateBlog(ctx context.Context, req *Upda
 func (*UnimplementedBlogServiceServer) DeleteBlog(ctx context.Context, req *DeleteBlogRequest) (*DeleteBlogResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteBlog not implemented")
 }
+func (*UnimplementedBlogServiceServer) DownloadImage(req *DownloadImageRequest, srv BlogService_DownloadImageServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadImage not implemented")
+}
 
 func RegisterBlogServiceServer(s *grpc.Server, srv BlogServiceServer) {
 	s.RegisterService(&_BlogService_serviceDesc, srv)
@@ -816,6 +1062,27 @@ func _BlogService_DeleteBlog_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlogService_DownloadImage_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadImageRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlogServiceServer).DownloadImage(m, &blogServiceDownloadImageServer{stream})
+}
+
+type BlogService_DownloadImageServer interface {
+	Send(*DownloadImageResponse) error
+	grpc.ServerStream
+}
+
+type blogServiceDownloadImageServer struct {
+	grpc.ServerStream
+}
+
+func (x *blogServiceDownloadImageServer) Send(m *DownloadImageResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _BlogService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "BlogService",
 	HandlerType: (*BlogServiceServer)(nil),
@@ -843,6 +1110,11 @@ var _BlogService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _BlogService_CreateBlog_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "DownloadImage",
+			Handler:       _BlogService_DownloadImage_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "blog.proto",
 }
@@ -7,6 +7,7 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -493,34 +494,41 @@ func init() {
 }
 
 var fileDescriptor_32c0044392f32579 = []byte{
-	// 432 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x8c, 0x93, 0x6f, 0x6f, 0xd3, 0x30,
-	0x10, 0xc6, 0x15, 0xa2, 0x8d, 0xe6, 0x92, 0x96, 0x61, 0x24, 0x54, 0x45, 0x08, 0x86, 0x11, 0x50,
-	0x69, 0x93, 0x41, 0x85, 0x7d, 0x80, 0x89, 0xbf, 0x2f, 0x60, 0x9a, 0xd2, 0x0f, 0x80, 0x92, 0x72,
-	0x8b, 0x22, 0x91, 0x38, 0xb3, 0x9d, 0x4a, 0x7c, 0x07, 0x3e, 0x34, 0xce, 0xd5, 0xf9, 0x53, 0x8a,
-	0xd4, 0xbd, 0xcb, 0x73, 0x7e, 0xee, 0x77, 0xee, 0x3d, 0x2e, 0x84, 0xb9, 0x42, 0x34, 0xa2, 0x56,
-	0xd2, 0x48, 0xfe, 0x15, 0x26, 0x5f, 0x5a, 0x59, 0x54, 0x39, 0x7b, 0x02, 0xc1, 0x4d, 0xa1, 0xb4,
-	0xb9, 0x4a, 0x4b, 0x9c, 0x7b, 0xa7, 0xde, 0x22, 0x48, 0x86, 0x02, 0x7b, 0x0a, 0xa0, 0x71, 0x2d,
-	0xab, 0x9f, 0x74, 0x7c, 0x8f, 0x8e, 0x47, 0x15, 0x7e, 0x01, 0x11, 0x91, 0x12, 0xbc, 0x6d, 0x50,
-	0x1b, 0xf6, 0x12, 0x26, 0xb9, 0x23, 0x13, 0x2c, 0x5c, 0x06, 0xa2, 0x1b, 0x95, 0xf4, 0x47, 0xfc,
-	0x0c, 0xa6, 0xae, 0x4d, 0xd7, 0xb2, 0xd2, 0xc8, 0x62, 0x98, 0x28, 0xf7, 0xed, 0x2e, 0xd1, 0x6b,
-	0xbe, 0x00, 0x58, 0x35, 0x65, 0x37, 0x21, 0x02, 0x2f, 0x25, 0x8b, 0x9f, 0x78, 0x69, 0xab, 0x32,
-	0xba, 0x96, 0x55, 0x19, 0x7f, 0x06, 0x21, 0x39, 0x1d, 0xf4, 0x04, 0x7c, 0xdd, 0x94, 0xce, 0xdc,
-	0x7e, 0xf2, 0x17, 0x10, 0x5c, 0x7f, 0xef, 0x48, 0x8f, 0xe1, 0xb8, 0x6a, 0xca, 0x0c, 0x95, 0x73,
-	0x38, 0xc5, 0xdf, 0x00, 0xb4, 0x26, 0x07, 0x79, 0x0e, 0x51, 0xad, 0x8a, 0x12, 0x7f, 0xdc, 0xa4,
-	0x6b, 0x23, 0x3b, 0x6f, 0x48, 0xb5, 0xcf, 0x54, 0xe2, 0xaf, 0x61, 0x7a, 0x45, 0xad, 0x87, 0xc8,
-	0x67, 0xf0, 0xe0, 0x72, 0x83, 0x2a, 0xcd, 0xb1, 0xc7, 0xcf, 0xe1, 0x7e, 0xba, 0x2d, 0x91, 0xd7,
-	0x4b, 0x3a, 0x69, 0xcd, 0x0f, 0x57, 0xb7, 0x4d, 0xaa, 0x30, 0x91, 0xd2, 0xfc, 0x9f, 0x7c, 0xd4,
-	0x93, 0xcf, 0x81, 0x8d, 0xcd, 0x0e, 0x6e, 0xdd, 0x9a, 0xaa, 0x8e, 0xed, 0xd4, 0xf2, 0x8f, 0xef,
-	0x62, 0x5b, 0xa1, 0xda, 0x14, 0x6b, 0x64, 0xaf, 0xe0, 0x88, 0x34, 0x9b, 0x8a, 0x71, 0x9c, 0xf1,
-	0x4c, 0xec, 0xc6, 0x74, 0x0a, 0xbe, 0x5d, 0x30, 0x0b, 0xc5, 0x10, 0x48, 0x1c, 0x89, 0xf1, 0xce,
-	0xcf, 0x21, 0xa0, 0x96, 0xcb, 0x5f, 0xf2, 0x10, 0xed, 0xad, 0xc7, 0x2e, 0x60, 0x7e, 0xdd, 0x2e,
-	0x72, 0xbb, 0xbe, 0x8f, 0xf6, 0x5d, 0x95, 0xb5, 0xd4, 0x85, 0x29, 0x64, 0xc5, 0x40, 0xf4, 0x51,
-	0xc5, 0xa1, 0x18, 0x12, 0xb1, 0x6d, 0x76, 0xc8, 0x37, 0x59, 0xe5, 0x77, 0xb9, 0xf2, 0xc2, 0x63,
-	0xef, 0x61, 0xf6, 0xc1, 0x72, 0x1b, 0x83, 0x6e, 0xf9, 0x6c, 0x26, 0x76, 0xf2, 0x8a, 0x4f, 0xc4,
-	0x3f, 0xb1, 0xd8, 0xae, 0xa5, 0x7b, 0xa2, 0x9f, 0xec, 0xc9, 0x6f, 0x59, 0xe1, 0xc1, 0x39, 0xf4,
-	0x73, 0x60, 0x48, 0x81, 0x31, 0xb1, 0x97, 0x5f, 0xfc, 0x48, 0xec, 0xc7, 0x94, 0x1d, 0xd3, 0xbf,
-	0xf2, 0xdd, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x75, 0x99, 0x58, 0xbc, 0xa4, 0x03, 0x00, 0x00,
+	// 531 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0xdd, 0x6e, 0xd3, 0x30,
+	0x14, 0xc7, 0xe5, 0x95, 0x6d, 0xcd, 0x49, 0x5b, 0x3a, 0x17, 0xa6, 0x12, 0x26, 0x3e, 0x8c, 0x10,
+	0x15, 0x9d, 0x9c, 0x52, 0xc6, 0x0d, 0xe2, 0x66, 0xe2, 0x5b, 0xa2, 0x53, 0x95, 0x3e, 0x00, 0x72,
+	0x8b, 0x17, 0x45, 0x5a, 0xe2, 0xd4, 0x76, 0x2a, 0x21, 0xc4, 0x0d, 0xb7, 0x5c, 0xf2, 0x68, 0xbc,
+	0x02, 0x0f, 0x82, 0x72, 0xea, 0xa6, 0xdd, 0x86, 0x54, 0xee, 0x72, 0xce, 0xf9, 0x9f, 0xdf, 0xb1,
+	0xfd, 0x3f, 0x2d, 0xf8, 0xb1, 0x96, 0xd2, 0xf2, 0x5c, 0x2b, 0xab, 0x82, 0xa3, 0x58, 0xa9, 0xf8,
+	0x42, 0x86, 0x22, 0x4f, 0x42, 0x91, 0x65, 0xca, 0x0a, 0x9b, 0xa8, 0xcc, 0x2c, 0xab, 0xec, 0x03,
+	0xd4, 0xdf, 0x97, 0xe2, 0x24, 0x8b, 0xe9, 0x11, 0x78, 0xe7, 0x89, 0x36, 0xf6, 0x4c, 0xa4, 0xb2,
+	0x4b, 0x1e, 0x90, 0x9e, 0x17, 0xad, 0x13, 0xf4, 0x1e, 0x80, 0x91, 0x33, 0x95, 0x7d, 0xc1, 0xf2,
+	0x0e, 0x96, 0x37, 0x32, 0xec, 0x05, 0x34, 0x90, 0x14, 0xc9, 0x79, 0x21, 0x8d, 0xa5, 0x8f, 0xa1,
+	0x1e, 0x3b, 0x32, 0xc2, 0xfc, 0xa1, 0xc7, 0x57, 0xa3, 0xa2, 0xaa, 0xc4, 0xfa, 0xd0, 0x74, 0x6d,
+	0x26, 0x57, 0x99, 0x91, 0x34, 0x80, 0xba, 0x76, 0xdf, 0xee, 0x10, 0x55, 0xcc, 0x7a, 0x00, 0x93,
+	0x22, 0x5d, 0x4d, 0x68, 0x00, 0x11, 0x28, 0xa9, 0x45, 0x44, 0x94, 0xd1, 0x14, 0x8f, 0x55, 0x8b,
+	0xc8, 0x94, 0xdd, 0x07, 0x1f, 0x95, 0x0e, 0xda, 0x86, 0x9a, 0x29, 0x52, 0x27, 0x2e, 0x3f, 0xd9,
+	0x23, 0xf0, 0xc6, 0xa3, 0x15, 0xe9, 0x10, 0xf6, 0xb2, 0x22, 0x9d, 0x4a, 0xed, 0x14, 0x2e, 0x62,
+	0x21, 0xc0, 0x78, 0x54, 0x41, 0x1e, 0x42, 0x23, 0xd7, 0x49, 0x2a, 0x3f, 0x9f, 0x8b, 0x99, 0x55,
+	0x2b, 0xad, 0x8f, 0xb9, 0x77, 0x98, 0x62, 0x4f, 0xa0, 0x79, 0x86, 0xad, 0xdb, 0xc8, 0x7d, 0xb8,
+	0x79, 0xba, 0x90, 0x5a, 0xc4, 0xb2, 0xc2, 0x77, 0x61, 0x5f, 0x2c, 0x53, 0xa8, 0x25, 0xd1, 0x2a,
+	0x64, 0x7d, 0x38, 0x98, 0xcc, 0x0b, 0xa1, 0x65, 0xa4, 0x94, 0xfd, 0x37, 0x79, 0xb7, 0x22, 0x1f,
+	0x03, 0xdd, 0x14, 0x3b, 0xf8, 0x21, 0xec, 0x19, 0xcc, 0x3a, 0xb6, 0x8b, 0x86, 0x3f, 0x6f, 0x38,
+	0xdb, 0x26, 0x52, 0x2f, 0x92, 0x99, 0xa4, 0xaf, 0x60, 0x17, 0x63, 0xda, 0xe4, 0x9b, 0x76, 0x06,
+	0x2d, 0x7e, 0xc9, 0x26, 0x76, 0xeb, 0xc7, 0xef, 0x3f, 0xbf, 0x76, 0x5a, 0xcc, 0x0b, 0x17, 0xcf,
+	0x42, 0x74, 0xf3, 0x25, 0x79, 0x4a, 0x4f, 0xa0, 0x36, 0x29, 0x52, 0xea, 0xf3, 0xb5, 0x4d, 0x41,
+	0x83, 0x6f, 0x38, 0xc1, 0x28, 0xf6, 0x35, 0xd8, 0x7e, 0xd9, 0x67, 0x8a, 0xb4, 0xec, 0xfa, 0x08,
+	0x1e, 0xc2, 0x4f, 0x2f, 0xd4, 0xd6, 0xb9, 0x77, 0xb1, 0xff, 0x36, 0x6b, 0xaf, 0xe7, 0x1a, 0xab,
+	0xa5, 0x28, 0x41, 0x03, 0x42, 0x23, 0xe8, 0x8e, 0x4b, 0x3f, 0x96, 0x2e, 0xbc, 0x91, 0x33, 0x95,
+	0xe6, 0xca, 0x24, 0xe5, 0xca, 0x53, 0xe0, 0x95, 0xe3, 0x81, 0xcf, 0xc7, 0xa3, 0xab, 0x4c, 0xda,
+	0x29, 0x99, 0x68, 0xa7, 0x09, 0xbf, 0x2d, 0x9f, 0xf3, 0xfb, 0x80, 0xd0, 0x63, 0xf0, 0x3e, 0xa9,
+	0x2c, 0xfe, 0x9f, 0x67, 0xe9, 0x11, 0x7a, 0x02, 0xad, 0xd7, 0x2a, 0xcd, 0x0b, 0x2b, 0x9d, 0xc1,
+	0xb4, 0xc5, 0x2f, 0xed, 0x44, 0xd0, 0xe6, 0x57, 0xac, 0xef, 0x11, 0x3a, 0x74, 0x3f, 0x83, 0xb7,
+	0x0b, 0xa9, 0xbf, 0xaa, 0x4c, 0x6e, 0x9d, 0x33, 0x20, 0x74, 0x0c, 0xb0, 0x76, 0x9a, 0x52, 0x7e,
+	0x6d, 0x47, 0x82, 0x0e, 0xbf, 0xbe, 0x0a, 0xec, 0x0e, 0xde, 0xb6, 0x43, 0x0f, 0xd0, 0x81, 0xb9,
+	0xb6, 0xd5, 0x5d, 0xa7, 0x7b, 0xf8, 0xa7, 0xf0, 0xfc, 0xef, 0x00, 0xeb, 0x7e, 0x88, 0xac, 0x41,
+	0x04, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -538,7 +546,7 @@ type GreetServiceClient interface {
 	Greet(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (*GreetResponse, error)
 	// Exercise: I'll add this one here because I'm lazy
 	Sum(ctx context.Context, in *SumRequest, opts ...grpc.CallOption) (*SumResponse, error)
-	// Server streaming RPC
+	// Server streaming RPC - over REST the responses arrive as newline delimited JSON
 	GreetAlot(ctx context.Context, in *GreetRequest, opts ...grpc.CallOption) (GreetService_GreetAlotClient, error)
 	// Exercise:
 	PrimeNumberDecomposition(ctx context.Context, in *PMRequest, opts ...grpc.CallOption) (GreetService_PrimeNumberDecompositionClient, error)
@@ -755,7 +763,7 @@ type GreetServiceServer interface {
 	Greet(context.Context, *GreetRequest) (*GreetResponse, error)
 	// Exercise: I'll add this one here because I'm lazy
 	Sum(context.Context, *SumRequest) (*SumResponse, error)
-	// Server streaming RPC
+	// Server streaming RPC - over REST the responses arrive as newline delimited JSON
 	GreetAlot(*GreetRequest, GreetService_GreetAlotServer) error
 	// Exercise:
 	PrimeNumberDecomposition(*PMRequest, GreetService_PrimeNumberDecompositionServer) error
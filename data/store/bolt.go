@@ -0,0 +1,277 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// lockTimeout - how long to wait for another process holding the file
+const lockTimeout = time.Second
+
+// Bolt - Store in a single file, for development and tests. Documents are
+// kept as BSON under their ObjectID, whose bytes sort by creation time.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt - opens or creates the store at path. Only one process can have it open.
+func NewBolt(path string) (*Bolt, error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %v : %v", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+
+		for _, name := range []string{blogCollection, auditCollection} {
+
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Bolt{db: db}, nil
+}
+
+// InsertBlog -
+func (s *Bolt) InsertBlog(ctx context.Context, blog *Blog) (primitive.ObjectID, error) {
+
+	if err := ctx.Err(); err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	doc := *blog
+	doc.ID = primitive.NewObjectID()
+
+	return doc.ID, s.put(blogCollection, doc.ID, &doc)
+}
+
+// FindBlog -
+func (s *Bolt) FindBlog(ctx context.Context, id primitive.ObjectID) (*Blog, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	blog := new(Blog)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+
+		raw := tx.Bucket([]byte(blogCollection)).Get(id[:])
+
+		if raw == nil {
+			return ErrNotFound
+		}
+
+		return bson.Unmarshal(raw, blog)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blog, nil
+}
+
+// ListBlogs -
+func (s *Bolt) ListBlogs(ctx context.Context) ([]Blog, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var blogs []Blog
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+
+		return tx.Bucket([]byte(blogCollection)).ForEach(func(_, raw []byte) error {
+
+			var blog Blog
+
+			if err := bson.Unmarshal(raw, &blog); err != nil {
+				return err
+			}
+
+			blogs = append(blogs, blog)
+
+			return nil
+		})
+	})
+
+	return blogs, err
+}
+
+// ReplaceBlog -
+func (s *Bolt) ReplaceBlog(ctx context.Context, blog *Blog) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	raw, err := bson.Marshal(blog)
+
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+
+		b := tx.Bucket([]byte(blogCollection))
+
+		if b.Get(blog.ID[:]) == nil {
+			return ErrNotFound
+		}
+
+		return b.Put(blog.ID[:], raw)
+	})
+}
+
+// DeleteBlog -
+func (s *Bolt) DeleteBlog(ctx context.Context, id primitive.ObjectID) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+
+		b := tx.Bucket([]byte(blogCollection))
+
+		if b.Get(id[:]) == nil {
+			return ErrNotFound
+		}
+
+		return b.Delete(id[:])
+	})
+}
+
+// AppendAudit -
+func (s *Bolt) AppendAudit(ctx context.Context, rec *AuditRecord) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	doc := *rec
+	doc.ID = primitive.NewObjectID()
+
+	return s.put(auditCollection, doc.ID, &doc)
+}
+
+// ListAudit - walks the trail backwards from q.Before (or the end)
+func (s *Bolt) ListAudit(ctx context.Context, q AuditQuery) ([]AuditRecord, error) {
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var records []AuditRecord
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+
+		c := tx.Bucket([]byte(auditCollection)).Cursor()
+
+		var k, raw []byte
+
+		if q.Before.IsZero() {
+			k, raw = c.Last()
+		} else {
+
+			// Seek lands on Before or the first key after it
+			k, raw = c.Seek(q.Before[:])
+
+			if k == nil {
+				k, raw = c.Last()
+			}
+
+			for k != nil && bytes.Compare(k, q.Before[:]) >= 0 {
+				k, raw = c.Prev()
+			}
+		}
+
+		for ; k != nil; k, raw = c.Prev() {
+
+			var rec AuditRecord
+
+			if err := bson.Unmarshal(raw, &rec); err != nil {
+				return err
+			}
+
+			if !q.matches(&rec) {
+				continue
+			}
+
+			records = append(records, rec)
+
+			if q.Limit > 0 && len(records) == q.Limit {
+				break
+			}
+		}
+
+		return nil
+	})
+
+	return records, err
+}
+
+// matches - the filters of q other than paging
+func (q AuditQuery) matches(rec *AuditRecord) bool {
+
+	switch {
+	case q.Actor != "" && rec.Actor != q.Actor:
+		return false
+	case q.BlogID != "" && rec.BlogID != q.BlogID:
+		return false
+	case !q.Since.IsZero() && rec.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !rec.Time.Before(q.Until):
+		return false
+	}
+
+	return true
+}
+
+// Ping - fails once the store is closed
+func (s *Bolt) Ping(ctx context.Context) error {
+	return s.db.View(func(*bolt.Tx) error { return nil })
+}
+
+// Close -
+func (s *Bolt) Close(ctx context.Context) error {
+	return s.db.Close()
+}
+
+func (s *Bolt) put(bucket string, id primitive.ObjectID, doc interface{}) error {
+
+	raw, err := bson.Marshal(doc)
+
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(bucket)).Put(id[:], raw)
+	})
+}
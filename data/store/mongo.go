@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collection names, also used by the migrations
+const (
+	blogCollection  = "blog"
+	auditCollection = "audit_events"
+)
+
+// Mongo - Store on a mongo database
+type Mongo struct {
+	db *mongo.Database
+}
+
+// NewMongo - returns a Store keeping its collections in db
+func NewMongo(db *mongo.Database) *Mongo {
+	return &Mongo{db: db}
+}
+
+// InsertBlog -
+func (m *Mongo) InsertBlog(ctx context.Context, blog *Blog) (primitive.ObjectID, error) {
+
+	res, err := m.db.Collection(blogCollection).InsertOne(ctx, blog)
+
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindBlog -
+func (m *Mongo) FindBlog(ctx context.Context, id primitive.ObjectID) (*Blog, error) {
+
+	blog := new(Blog)
+
+	err := m.db.Collection(blogCollection).FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(blog)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	return blog, err
+}
+
+// ListBlogs -
+func (m *Mongo) ListBlogs(ctx context.Context) ([]Blog, error) {
+
+	// ids start with their creation time
+	cur, err := m.db.Collection(blogCollection).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var blogs []Blog
+
+	return blogs, cur.All(ctx, &blogs)
+}
+
+// ReplaceBlog -
+func (m *Mongo) ReplaceBlog(ctx context.Context, blog *Blog) error {
+
+	res, err := m.db.Collection(blogCollection).ReplaceOne(ctx, bson.D{{Key: "_id", Value: blog.ID}}, blog)
+
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteBlog -
+func (m *Mongo) DeleteBlog(ctx context.Context, id primitive.ObjectID) error {
+
+	res, err := m.db.Collection(blogCollection).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// AppendAudit -
+func (m *Mongo) AppendAudit(ctx context.Context, rec *AuditRecord) error {
+
+	_, err := m.db.Collection(auditCollection).InsertOne(ctx, rec)
+
+	return err
+}
+
+// ListAudit -
+func (m *Mongo) ListAudit(ctx context.Context, q AuditQuery) ([]AuditRecord, error) {
+
+	filter := bson.D{}
+
+	if q.Actor != "" {
+		filter = append(filter, bson.E{Key: "actor", Value: q.Actor})
+	}
+
+	if q.BlogID != "" {
+		filter = append(filter, bson.E{Key: "blog_id", Value: q.BlogID})
+	}
+
+	window := bson.D{}
+
+	if !q.Since.IsZero() {
+		window = append(window, bson.E{Key: "$gte", Value: q.Since})
+	}
+
+	if !q.Until.IsZero() {
+		window = append(window, bson.E{Key: "$lt", Value: q.Until})
+	}
+
+	if len(window) > 0 {
+		filter = append(filter, bson.E{Key: "time", Value: window})
+	}
+
+	if !q.Before.IsZero() {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$lt", Value: q.Before}}})
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit))
+	}
+
+	cur, err := m.db.Collection(auditCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var records []AuditRecord
+
+	return records, cur.All(ctx, &records)
+}
+
+// Ping -
+func (m *Mongo) Ping(ctx context.Context) error {
+	return m.db.Client().Ping(ctx, nil)
+}
+
+// Close - disconnects the client, shared by every database on it
+func (m *Mongo) Close(ctx context.Context) error {
+	return m.db.Client().Disconnect(ctx)
+}
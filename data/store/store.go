@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound - the blog does not exist
+var ErrNotFound = errors.New("document not found")
+
+// Blog - a stored blog. The bson tags are the document layout in mongo
+// and the encoding of the embedded store.
+type Blog struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	CoverImage string             `bson:"cover_image"`
+	AuthorID   string             `bson:"author_id"`
+	Title      string             `bson:"title"`
+	Body       string             `bson:"body"`
+
+	// Variants - renditions of the cover image, CoverImage is the original.
+	// Blogs created before image processing have none.
+	Variants []Variant `bson:"variants,omitempty"`
+}
+
+// Variant - a processed rendition of a cover image
+type Variant struct {
+	Name        string `bson:"name"`
+	Key         string `bson:"key"`
+	ContentType string `bson:"content_type"`
+	Width       int    `bson:"width"`
+	Height      int    `bson:"height"`
+}
+
+// AuditRecord - one entry of the append only audit trail
+type AuditRecord struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Time        time.Time          `bson:"time"`
+	Action      string             `bson:"action"`
+	BlogID      string             `bson:"blog_id,omitempty"`
+	Actor       string             `bson:"actor,omitempty"`
+	Certificate string             `bson:"certificate,omitempty"`
+	Peer        string             `bson:"peer,omitempty"`
+	RequestID   string             `bson:"request_id,omitempty"`
+	Outcome     string             `bson:"outcome"`
+	Error       string             `bson:"error,omitempty"`
+	Before      map[string]string  `bson:"before,omitempty"`
+	After       map[string]string  `bson:"after,omitempty"`
+}
+
+// AuditQuery - filters of ListAudit, zero values match everything
+type AuditQuery struct {
+	Actor  string
+	BlogID string
+
+	// Since (inclusive) and Until (exclusive) bound the record time
+	Since time.Time
+	Until time.Time
+
+	// Before - only records older than this one, for paging
+	Before primitive.ObjectID
+
+	Limit int
+}
+
+// Store - blog persistence. Implementations agree on ids (ObjectIDs made
+// by the store), on order (oldest first) and on ErrNotFound.
+type Store interface {
+	// InsertBlog - stores a new blog and returns its id
+	InsertBlog(ctx context.Context, blog *Blog) (primitive.ObjectID, error)
+
+	// FindBlog - the blog with id, ErrNotFound when there is none
+	FindBlog(ctx context.Context, id primitive.ObjectID) (*Blog, error)
+
+	// ListBlogs - every blog, oldest first
+	ListBlogs(ctx context.Context) ([]Blog, error)
+
+	// ReplaceBlog - overwrites the blog with blog.ID, ErrNotFound when there is none
+	ReplaceBlog(ctx context.Context, blog *Blog) error
+
+	// DeleteBlog - removes the blog with id, ErrNotFound when there is none
+	DeleteBlog(ctx context.Context, id primitive.ObjectID) error
+
+	// AppendAudit - adds a record to the audit trail
+	AppendAudit(ctx context.Context, rec *AuditRecord) error
+
+	// ListAudit - records matching q, newest first
+	ListAudit(ctx context.Context, q AuditQuery) ([]AuditRecord, error)
+
+	// Ping - reports whether the store can serve requests
+	Ping(ctx context.Context) error
+
+	// Close - releases the store, it is not usable afterwards
+	Close(ctx context.Context) error
+}
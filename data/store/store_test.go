@@ -0,0 +1,135 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestBolt(t *testing.T) {
+
+	s, err := NewBolt(filepath.Join(t.TempDir(), "test.db"))
+
+	if err != nil {
+		t.Fatalf("cannot open store : %v", err)
+	}
+
+	defer s.Close(context.Background())
+
+	testStore(t, s)
+}
+
+// TestMongo - the same checks against a local mongo, skipped without one
+func TestMongo(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
+
+	if err == nil {
+		err = client.Ping(ctx, nil)
+	}
+
+	if err != nil {
+		t.Skipf("mongodb is not reachable : %v", err)
+	}
+
+	db := client.Database("grpcourse_store_test")
+
+	defer func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	}()
+
+	testStore(t, NewMongo(db))
+}
+
+func testStore(t *testing.T, s Store) {
+
+	ctx := context.Background()
+
+	// 1. blogs come back in creation order
+	var ids []primitive.ObjectID
+
+	for _, title := range []string{"first", "second", "third"} {
+
+		id, err := s.InsertBlog(ctx, &Blog{AuthorID: "1001", Title: title})
+
+		if err != nil {
+			t.Fatalf("cannot insert %v : %v", title, err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	blogs, err := s.ListBlogs(ctx)
+
+	if err != nil || len(blogs) != 3 || blogs[0].Title != "first" || blogs[2].Title != "third" {
+		t.Fatalf("unexpected list %+v : %v", blogs, err)
+	}
+
+	// 2. replace and find
+	if err := s.ReplaceBlog(ctx, &Blog{ID: ids[1], AuthorID: "1001", Title: "second, edited"}); err != nil {
+		t.Fatalf("cannot replace : %v", err)
+	}
+
+	if blog, err := s.FindBlog(ctx, ids[1]); err != nil || blog.Title != "second, edited" || blog.ID != ids[1] {
+		t.Errorf("unexpected blog %+v : %v", blog, err)
+	}
+
+	// 3. missing blogs
+	missing := primitive.NewObjectID()
+
+	if _, err := s.FindBlog(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("find : expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.ReplaceBlog(ctx, &Blog{ID: missing}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("replace : expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.DeleteBlog(ctx, ids[0]); err != nil {
+		t.Fatalf("cannot delete : %v", err)
+	}
+
+	if err := s.DeleteBlog(ctx, ids[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("delete twice : expected ErrNotFound, got %v", err)
+	}
+
+	// 4. the audit trail, newest first, filtered and paged
+	start := time.Now().UTC().Truncate(time.Millisecond)
+
+	for i, actor := range []string{"alice", "bob", "alice", "alice"} {
+
+		rec := &AuditRecord{Time: start.Add(time.Duration(i) * time.Second), Action: "UpdateBlog", Actor: actor, Outcome: "OK"}
+
+		if err := s.AppendAudit(ctx, rec); err != nil {
+			t.Fatalf("cannot append audit record : %v", err)
+		}
+	}
+
+	page, err := s.ListAudit(ctx, AuditQuery{Actor: "alice", Limit: 2})
+
+	if err != nil || len(page) != 2 || !page[0].Time.Equal(start.Add(3*time.Second)) {
+		t.Fatalf("unexpected first page %+v : %v", page, err)
+	}
+
+	rest, err := s.ListAudit(ctx, AuditQuery{Actor: "alice", Before: page[1].ID})
+
+	if err != nil || len(rest) != 1 || !rest[0].Time.Equal(start) {
+		t.Errorf("unexpected second page %+v : %v", rest, err)
+	}
+
+	window, err := s.ListAudit(ctx, AuditQuery{Since: start.Add(time.Second), Until: start.Add(3 * time.Second)})
+
+	if err != nil || len(window) != 2 || window[0].Actor != "alice" || window[1].Actor != "bob" {
+		t.Errorf("unexpected window %+v : %v", window, err)
+	}
+}
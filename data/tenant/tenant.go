@@ -0,0 +1,171 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collection - the registry, kept in the shared database
+const collection = "tenants"
+
+// knownTTL - how long a running server trusts that a tenant exists. A
+// deleted tenant keeps being served for at most this long.
+const knownTTL = 30 * time.Second
+
+// ErrExists, ErrNotFound - returned by Create and Delete
+var (
+	ErrExists   = errors.New("tenant already exists")
+	ErrNotFound = errors.New("tenant not found")
+)
+
+// ids end up in database names and image paths - keep them short and plain
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Valid - reports whether id may name a tenant
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+// Tenant - a team with its own database and image directory
+type Tenant struct {
+	ID      string    `bson:"_id"`
+	Name    string    `bson:"name,omitempty"`
+	Created time.Time `bson:"created"`
+}
+
+// Registry - the provisioned tenants and their databases
+type Registry struct {
+	coll   *mongo.Collection
+	prefix string
+
+	mu    sync.Mutex
+	known map[string]time.Time
+}
+
+// NewRegistry - tenants are listed in shared, their data lives in the
+// databases named prefix + tenant id on the same client
+func NewRegistry(shared *mongo.Database, prefix string) *Registry {
+	return &Registry{coll: shared.Collection(collection), prefix: prefix, known: map[string]time.Time{}}
+}
+
+// Database - the database holding the data of tenant id
+func (r *Registry) Database(id string) *mongo.Database {
+	return r.coll.Database().Client().Database(r.prefix + id)
+}
+
+// Exists - reports whether id was provisioned, answered from memory for knownTTL
+func (r *Registry) Exists(ctx context.Context, id string) (bool, error) {
+
+	if !Valid(id) {
+		return false, nil
+	}
+
+	r.mu.Lock()
+	seen, ok := r.known[id]
+	r.mu.Unlock()
+
+	if ok && time.Since(seen) < knownTTL {
+		return true, nil
+	}
+
+	n, err := r.coll.CountDocuments(ctx, bson.D{{Key: "_id", Value: id}})
+
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n == 0 {
+		delete(r.known, id)
+		return false, nil
+	}
+
+	r.known[id] = time.Now()
+
+	return true, nil
+}
+
+// Create - registers tenant id, its database is created on first write
+func (r *Registry) Create(ctx context.Context, id, name string) (*Tenant, error) {
+
+	if !Valid(id) {
+		return nil, errors.New("tenant id must be 1-32 lowercase letters, digits or dashes")
+	}
+
+	t := &Tenant{ID: id, Name: name, Created: time.Now().UTC()}
+
+	if _, err := r.coll.InsertOne(ctx, t); err != nil {
+
+		if isDuplicate(err) {
+			return nil, ErrExists
+		}
+
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Delete - unregisters tenant id, drop also removes its database
+func (r *Registry) Delete(ctx context.Context, id string, drop bool) error {
+
+	res, err := r.coll.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	r.mu.Lock()
+	delete(r.known, id)
+	r.mu.Unlock()
+
+	if drop {
+		return r.Database(id).Drop(ctx)
+	}
+
+	return nil
+}
+
+// List - every tenant ordered by id
+func (r *Registry) List(ctx context.Context) ([]Tenant, error) {
+
+	cur, err := r.coll.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Tenant
+
+	return list, cur.All(ctx, &list)
+}
+
+func isDuplicate(err error) bool {
+
+	var we mongo.WriteException
+
+	if errors.As(err, &we) {
+
+		for _, e := range we.WriteErrors {
+
+			if e.Code == 11000 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -3,11 +3,13 @@ package main
 import (
 	"fmt"
 	"grpcourse/cmd"
+	"os"
 )
 
 func main() {
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Println(err)
+		os.Exit(1)
 	}
 }